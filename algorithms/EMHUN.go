@@ -1,8 +1,10 @@
 package algorithms
 
 import (
+	"EMHUNer/logger"
 	"EMHUNer/models"
 	"EMHUNer/utility"
+	"context"
 	"fmt"
 	"sort"
 )
@@ -10,17 +12,67 @@ import (
 type EMHUN struct {
 	Transactions       []*models.Transaction
 	MinUtility         float64
+	MinUtilityRatio    float64
+	TopK               int
+	Closed             bool             // chỉ trả về các HUI đóng (không có tập cha thực sự cùng support)
+	Maximal            bool             // chỉ trả về các HUI cực đại (không có tập cha thực sự là HUI); không dùng cùng TopK
+	SortOrder          models.SortOrder // thứ tự của các HUI trong Result (mặc định utility giảm dần)
+	Workers            int              // số goroutine tìm kiếm; 0 hoặc 1 là tuần tự, âm là dùng mọi CPU
+	SplitDepth         int              // số tầng nhánh được chia cho các worker khi Workers > 1 (mặc định 1)
+	MaxTIDs            int              // số TID tối đa của các giao dịch chứa mỗi HUI được ghi lại; 0 là không ghi, âm là ghi tất cả
+	ItemNames          models.ItemNames // tên item (theo ID trong dataset) gắn vào mỗi HUI để in kết quả
+	Sink               Sink             // nếu khác nil, nhận từng HUI ngay khi được xác nhận (xem RunContext)
+	PhaseObserver      PhaseObserver    // nếu khác nil, được báo khi bắt đầu từng giai đoạn của Run
+	TotalUtility       float64
 	Rho, Delta, Eta    map[int]bool
 	SortedSecondary    []int
 	SortedEta          []int
 	PrimaryItems       []int
-	UtilityArray       *models.UtilityArray
+	RTWU               map[int]float64      // RTWU theo mã item gốc
+	UtilityArray       *models.UtilityArray // RTWU và RSU theo chỉ số item đã đánh lại (xem remapItems)
 	SearchAlgorithms   *SearchAlgorithms
 	ItemTransactionMap map[int][]*models.Transaction
+	Logger             logger.Logger
+
+	positiveCount    map[int]int
+	negativeCount    map[int]int
+	zeroCount        map[int]int // số lần xuất hiện với utility 0
+	transactionsByID map[int]*models.Transaction
+	itemPositions    map[int]map[int]int // vị trí của từng giao dịch (theo TID) trong ItemTransactionMap[item]
+	ownsTransactions bool                // Transactions đã được sao chép khỏi mảng của người gọi (xem ApplyUpdate)
+	nextTID          int
+	remap            *itemRemap                    // đánh số lại các item Secondary ∪ η của lần tìm kiếm hiện tại
+	searchMap        map[int][]*models.Transaction // ItemTransactionMap theo chỉ số item đã đánh lại
+	searchByTID      map[int]*models.Transaction   // bản sao đã đánh lại số của các giao dịch trong searchMap, theo TID
+	incomplete       bool                          // lần Run gần nhất bị dừng giữa chừng
+	streamed         bool                          // các HUI của lần Run gần nhất đã được gửi vào Sink mà không giữ lại
+	phases           PhaseTimings
+}
+
+// Result chứa các HUI và thống kê của một lần chạy Run
+type Result struct {
+	HighUtilityItemsets *models.ResultSet // đã chuẩn hóa, không trùng lặp và sắp theo EMHUN.SortOrder
+	Stats               Stats
+	Incomplete          bool  // tìm kiếm bị dừng do context bị hủy hoặc hết hạn; chỉ có các HUI tìm được đến lúc đó
+	CompletedBranches   []int // các item Primary ở tầng đầu có nhánh đã được duyệt hết (theo thứ tự duyệt)
+	Err                 error // lỗi do Sink trả về; tìm kiếm dừng lại ở HUI đầu tiên bị lỗi
+}
+
+// Stats là thống kê tổng quát của một lần chạy
+type Stats struct {
+	Transactions        int
+	TotalUtility        float64
+	MinUtility          float64
+	Rho, Delta, Eta     int
+	Secondary           int
+	Primary             int
+	HighUtilityItemsets int
+	Search              SearchStats
+	Phases              PhaseTimings
 }
 
 func NewEMHUN(transactions []*models.Transaction, minUtility float64) *EMHUN {
-	utilityArray := models.NewUtilityArray()
+	utilityArray := models.NewUtilityArray(0)
 
 	return &EMHUN{
 		Transactions:     transactions,
@@ -28,76 +80,292 @@ func NewEMHUN(transactions []*models.Transaction, minUtility float64) *EMHUN {
 		Rho:              make(map[int]bool),
 		Delta:            make(map[int]bool),
 		Eta:              make(map[int]bool),
+		RTWU:             make(map[int]float64),
 		UtilityArray:     utilityArray,
 		SearchAlgorithms: NewSearchAlgorithms(utilityArray),
+		Logger:           logger.Nop(),
+	}
+}
+
+// NewEMHUNWithRatio tạo EMHUN với ngưỡng tương đối: minUtility = ratio × tổng utility dương của
+// cơ sở dữ liệu. Ngưỡng tuyệt đối được xác định khi gọi ResolveMinUtility hoặc Run.
+func NewEMHUNWithRatio(transactions []*models.Transaction, ratio float64) *EMHUN {
+	e := NewEMHUN(transactions, 0)
+	e.MinUtilityRatio = ratio
+	return e
+}
+
+// ResolveMinUtility tính tổng utility dương của cơ sở dữ liệu và, nếu dùng ngưỡng tương đối,
+// chuyển nó thành ngưỡng tuyệt đối. Trả về ngưỡng tuyệt đối.
+func (e *EMHUN) ResolveMinUtility() float64 {
+	e.TotalUtility = utility.CalculateTotalPositiveUtility(e.Transactions)
+	if e.MinUtilityRatio > 0 {
+		e.MinUtility = e.MinUtilityRatio * e.TotalUtility
+	}
+	return e.MinUtility
+}
+
+// SetLogger gắn logger cho EMHUN và thuật toán tìm kiếm của nó
+func (e *EMHUN) SetLogger(log logger.Logger) {
+	if log == nil {
+		log = logger.Nop()
 	}
+	e.Logger = log
+	e.SearchAlgorithms.Logger = log
 }
 
-func (e *EMHUN) Run() {
+func (e *EMHUN) Run() *Result {
+	return e.RunContext(context.Background())
+}
 
-	fmt.Println("Running EMHUN...")
+// RunContext chạy EMHUN và dừng tìm kiếm khi ctx bị hủy hoặc hết hạn. Khi đó kết quả chứa các HUI
+// tìm được đến lúc dừng, Incomplete = true và CompletedBranches liệt kê các nhánh tầng đầu đã duyệt hết:
+// mọi HUI bắt đầu bằng các nhánh đó (theo thứ tự xử lý) đều đã được tìm thấy.
+//
+// Nếu e.Sink khác nil, ở chế độ thường và closed mỗi HUI được gửi vào Sink ngay khi tìm thấy (itemset
+// đã sắp tăng dần, theo thứ tự tìm thấy) và không được giữ lại: Result không chứa HUI nào, chỉ có số
+// lượng trong Stats, và không thể cập nhật tăng dần sau đó. Ở chế độ top-k và maximal, tập HUI chỉ xác
+// định khi tìm kiếm kết thúc nên được gửi vào Sink sau cùng, theo thứ tự của Result.
+func (e *EMHUN) RunContext(ctx context.Context) *Result {
+	s := e.SearchAlgorithms
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.done = ctx.Done()
+	s.abort = cancel
+	s.completed = nil
+	s.sinkErr = nil
+	s.emitted = 0
+	s.stats = SearchStats{}
+	e.phases = PhaseTimings{}
+	e.streamed = e.Sink != nil && e.TopK == 0 && !e.Maximal
+	e.ownsTransactions = false
+	if e.streamed {
+		s.sink = e.Sink
+	}
+	defer func() {
+		s.done = nil
+		s.abort = nil
+		s.sink = nil
+	}()
 
-	e.ClassifyItems()
+	e.Logger.Logf(logger.Summary, "Running EMHUN...\n")
+
+	e.ResolveMinUtility()
+	if e.MinUtilityRatio > 0 {
+		e.Logger.Logf(logger.Summary, "minUtility = %.4f × %.2f = %.2f\n", e.MinUtilityRatio, e.TotalUtility, e.MinUtility)
+	}
+
+	e.timePhase("classification", &e.phases.Classification, e.ClassifyItems)
 
 	// In ra nội dung của ItemTransactionMap
-	fmt.Println("\nAfter classify, we have:")
 	e.printClassification()
 	combinedSet := e.unionKeys(e.Rho, e.Delta)
-	fmt.Println("\nCalculating RTWU for all items in (ρ ∪ δ):")
-	utility.CalculateRTWUForAllItems(e.ItemTransactionMap, combinedSet, e.Eta, e.UtilityArray)
+	e.Logger.Logf(logger.Summary, "Calculating RTWU for all items in (ρ ∪ δ)...\n")
+	e.timePhase("rtwu", &e.phases.RTWU, func() {
+		e.RTWU = make(map[int]float64)
+		utility.CalculateRTWUForAllItems(e.ItemTransactionMap, combinedSet, e.Eta, e.RTWU)
+	})
+
+	e.SearchAlgorithms.TopK = e.TopK
+	e.SearchAlgorithms.Closed = e.Closed
+	e.SearchAlgorithms.MaxTIDs = e.MaxTIDs
+	e.SearchAlgorithms.ItemNames = e.ItemNames
+	e.SearchAlgorithms.Maximal = e.Maximal && e.TopK == 0
+	if e.Maximal && e.TopK > 0 {
+		e.Logger.Logf(logger.Summary, "Maximal mode is ignored in top-k mode\n")
+	}
+	if e.TopK > 0 {
+		// Item đơn có utility cao chưa chắc là itemset đóng nên ở chế độ closed ngưỡng bắt đầu từ mức nhỏ nhất
+		e.MinUtility = minPositiveUtility
+		if !e.Closed {
+			e.MinUtility = e.initialTopKThreshold(combinedSet)
+		}
+		e.Logger.Logf(logger.Summary, "Top-%d mode: initial minUtility = %.2f\n", e.TopK, e.MinUtility)
+	}
+
+	e.prepareSearch(combinedSet)
+
+	e.Logger.Logf(logger.Summary, "Starting HUI Search...\n")
+	e.timePhase("search", &e.phases.Search, e.search)
+	e.incomplete = ctx.Err() != nil
+	if e.incomplete {
+		reason := ctx.Err()
+		if s.sinkErr != nil {
+			reason = fmt.Errorf("sink: %w", s.sinkErr)
+		}
+		e.Logger.Logf(logger.Summary, "Search stopped early: %v (%d of %d top-level branches completed)\n",
+			reason, len(s.completed), len(e.PrimaryItems))
+	}
+	if e.TopK > 0 {
+		e.MinUtility = e.SearchAlgorithms.finishTopK(e.MinUtility)
+		e.Logger.Logf(logger.Summary, "Top-%d mode: final minUtility = %.2f\n", e.TopK, e.MinUtility)
+	}
+
+	result := e.result()
+	result.Incomplete = e.incomplete
+	result.CompletedBranches = e.completedBranches()
+	if e.Sink != nil && !e.streamed {
+		for _, hui := range result.HighUtilityItemsets.Items() {
+			if err := e.Sink.Emit(hui); err != nil {
+				s.sinkErr = err
+				break
+			}
+		}
+	}
+	result.Err = s.sinkErr
+	return result
+}
+
+// completedBranches trả về các item Primary đã duyệt hết nhánh, theo thứ tự của PrimaryItems
+func (e *EMHUN) completedBranches() []int {
+	done := convertSliceToMap(e.remap.originalItems(e.SearchAlgorithms.completed))
+	var completed []int
+	for _, item := range e.PrimaryItems {
+		if done[item] {
+			completed = append(completed, item)
+		}
+	}
+	return completed
+}
 
-	secondaryItems := e.getSecondaryItems(combinedSet, e.UtilityArray, e.MinUtility)
+// search duyệt cây tìm kiếm từ các item Primary, song song khi Workers khác 0 và 1.
+// Việc tìm kiếm dùng chỉ số item đã đánh lại: Secondary là 0..|Secondary|-1, η là các chỉ số còn lại.
+func (e *EMHUN) search() {
+	s := e.SearchAlgorithms
+	secondary := span(0, len(e.SortedSecondary))
+	eta := span(len(e.SortedSecondary), len(e.remap.original))
+	primary := e.remap.denseItems(e.PrimaryItems)
+	if e.Workers == 0 || e.Workers == 1 {
+		s.Search(eta, make(map[int]bool), e.searchMap, primary, secondary, e.MinUtility)
+		return
+	}
+	s.Workers = e.Workers
+	s.SplitDepth = e.SplitDepth
+	s.SearchParallel(eta, make(map[int]bool), e.searchMap, primary, secondary, e.MinUtility)
+}
+
+// prepareSearch xác định Secondary(X), lọc ItemTransactionMap, đánh lại số các item còn lại rồi tính RSU
+// để tìm Primary(X). Được dùng bởi Run và bởi các lần cập nhật tăng dần.
+func (e *EMHUN) prepareSearch(combinedSet map[int]bool) {
+	var secondaryItems []int
+	e.timePhase("filtering", &e.phases.Filtering, func() {
+		secondaryItems = e.getSecondaryItems(combinedSet, e.RTWU, e.MinUtility)
+	})
+	e.SearchAlgorithms.stats.PrunedRTWU = int64(len(combinedSet) - len(secondaryItems))
 
-	e.SortedSecondary = e.sortItems(secondaryItems)
-	e.SortedEta = e.sortItems(e.keys(e.Eta))
+	e.timePhase("sorting", &e.phases.Sorting, func() {
+		e.SortedSecondary = e.sortItems(secondaryItems)
+		e.SortedEta = e.sortItems(e.keys(e.Eta))
+	})
 
 	// e.FilterTransactions(secondaryItemsMap, e.Eta)
-	e.RemoveUnwantedItemsInTransactionMap()
+	e.timePhase("filtering", &e.phases.Filtering, e.RemoveUnwantedItemsInTransactionMap)
 
-	e.SortItemsInTransactionsAndMap()
+	e.timePhase("sorting", &e.phases.Sorting, e.remapItems)
 
-	fmt.Println("\nSorting transactions by total RTWU:")
-	e.SortTransactionsByTWU()
-	// fmt.Println("\nTransactions after sorting by RTWU:")
+	e.Logger.Logf(logger.Summary, "Calculating RSU for each item in Secondary(X)...\n")
+	e.timePhase("rsu", &e.phases.RSU, func() {
+		utility.CalculateRSUForAllItems(e.searchMap, span(0, len(e.SortedSecondary)), e.UtilityArray)
+		e.PrimaryItems = nil
+		e.identifyPrimaryItems()
+	})
+	// Các item Secondary không thuộc Primary là các nhánh tầng đầu bị RSU cắt tỉa
+	e.SearchAlgorithms.stats.PrunedRSU += int64(len(e.SortedSecondary) - len(e.PrimaryItems))
+	e.Logger.Logf(logger.Summary, "Primary: %d items\n", len(e.PrimaryItems))
+	e.Logger.Logf(logger.Node, "Primary: %v\n", e.PrimaryItems)
+}
 
-	fmt.Println("\nCalculating RSU for each item in Secondary(X)...")
-	utility.CalculateRSUForAllItems(e.ItemTransactionMap, e.SortedSecondary, e.UtilityArray)
-	e.identifyPrimaryItems()
-	fmt.Println("Primary: ", e.PrimaryItems)
-	fmt.Println("\nStarting HUI Search...")
-	e.SearchAlgorithms.Search(e.SortedEta, make(map[int]bool), e.ItemTransactionMap, e.PrimaryItems, e.SortedSecondary, e.MinUtility)
+// remapItems đánh lại số các item Secondary ∪ η thành 0..n-1 theo thứ tự xử lý và dựng cơ sở dữ liệu
+// tìm kiếm theo các chỉ số đó. Các giao dịch gốc, ItemTransactionMap và các danh sách item của EMHUN
+// vẫn dùng mã item gốc; HUI được đổi lại sang mã gốc khi được ghi nhận.
+func (e *EMHUN) remapItems() {
+	e.remap = newItemRemap(e.SortedSecondary, e.SortedEta)
+	e.Logger.Logf(logger.Summary, "Remapping %d items and sorting transactions...\n", len(e.remap.original))
+	e.searchMap, e.searchByTID = e.remap.itemTransactionMap(e.ItemTransactionMap)
+
+	e.UtilityArray.Reset(len(e.remap.original))
+	for index, item := range e.remap.original {
+		e.UtilityArray.SetRTWU(index, e.RTWU[item])
+	}
+	e.SearchAlgorithms.remap = e.remap
+	e.SearchAlgorithms.transactionsByID = e.transactionsByID
+}
 
-	// In kết quả sau khi tìm High Utility Itemsets
-	fmt.Println("\nHUIs Found:")
+// result chuẩn hóa tập HUI hiện tại thành ResultSet và đóng gói cùng thống kê
+func (e *EMHUN) result() *Result {
+	huis := models.NewResultSet()
 	for _, hui := range e.SearchAlgorithms.HighUtilityItemsets {
-		fmt.Printf("Itemset: %v, Utility: %.2f\n", hui.Itemset, hui.Utility)
+		huis.Add(hui)
+	}
+	if duplicates := huis.Duplicates(); len(duplicates) > 0 {
+		e.Logger.Logf(logger.Summary, "Warning: %d duplicate itemsets discarded\n", len(duplicates))
+	}
+	huis.Sort(e.SortOrder)
+	count := huis.Len()
+	if e.streamed {
+		count = e.SearchAlgorithms.emitted
+	}
+	// Giữ bản sao đã chuẩn hóa cho các lần cập nhật tăng dần sau
+	e.SearchAlgorithms.HighUtilityItemsets = append([]*models.HighUtilityItemset(nil), huis.Items()...)
+
+	// In kết quả sau khi tìm High Utility Itemsets
+	stats := e.SearchAlgorithms.stats
+	e.Logger.Logf(logger.Summary, "Search: %d nodes (%d in SearchN), max depth %d, %d projected transactions\n",
+		stats.Nodes+stats.NodesN, stats.NodesN, stats.MaxDepth, stats.ProjectedTransactions)
+	e.Logger.Logf(logger.Summary, "Pruned: %d by RTWU, %d by RSU, %d by RLU, %d subsumed\n",
+		stats.PrunedRTWU, stats.PrunedRSU, stats.PrunedRLU, stats.PrunedSubsumed)
+	e.Logger.Logf(logger.Summary, "HUIs Found: %d\n", count)
+	if e.Logger.Enabled(logger.Node) {
+		for _, hui := range huis.Items() {
+			e.Logger.Logf(logger.Node, "Itemset: %v, Utility: %.2f\n", hui.Itemset, hui.Utility)
+		}
+	}
+
+	return &Result{
+		HighUtilityItemsets: huis,
+		Stats: Stats{
+			Transactions:        len(e.Transactions),
+			TotalUtility:        e.TotalUtility,
+			MinUtility:          e.MinUtility,
+			Rho:                 len(e.Rho),
+			Delta:               len(e.Delta),
+			Eta:                 len(e.Eta),
+			Secondary:           len(e.SortedSecondary),
+			Primary:             len(e.PrimaryItems),
+			HighUtilityItemsets: count,
+			Search:              stats,
+			Phases:              e.phases,
+		},
 	}
 }
 func (e *EMHUN) PrintItemTransactionMap() {
-	fmt.Println("ItemTransactionMap:")
+	e.Logger.Logf(logger.Node, "ItemTransactionMap:\n")
 	for item, transactions := range e.ItemTransactionMap {
-		fmt.Printf("Item %d appears in transactions:\n", item)
+		e.Logger.Logf(logger.Node, "Item %d appears in transactions:\n", item)
 		for _, transaction := range transactions {
-			fmt.Printf("   Items: %v, Utilities: %v\n", transaction.Items, transaction.Utilities)
+			e.Logger.Logf(logger.Node, "   Items: %v, Utilities: %v\n", transaction.Items, transaction.Utilities)
 		}
 	}
 }
 
 func (e *EMHUN) PrintTransactions() {
-	fmt.Println("---------------------<Transaction>-------------------------")
+	e.Logger.Logf(logger.Node, "---------------------<Transaction>-------------------------\n")
 	for i, transaction := range e.Transactions {
-		fmt.Printf("Transaction %d: %s\n", i+1, transaction)
+		e.Logger.Logf(logger.Node, "Transaction %d: %s\n", i+1, transaction)
 	}
-	fmt.Println("-----------------------------------------------------------")
+	e.Logger.Logf(logger.Node, "-----------------------------------------------------------\n")
 }
 
 func (e *EMHUN) ClassifyItems() {
-	// Tạo map để lưu các item có utility dương và âm
-	hasPositive := make(map[int]bool)
-	hasNegative := make(map[int]bool)
+	// Đếm số lần mỗi item xuất hiện với utility dương, âm và bằng 0
+	e.positiveCount = make(map[int]int)
+	e.negativeCount = make(map[int]int)
+	e.zeroCount = make(map[int]int)
 
 	// Khởi tạo ItemTransactionMap để lưu danh sách giao dịch cho từng item
 	e.ItemTransactionMap = make(map[int][]*models.Transaction)
+	e.itemPositions = make(map[int]map[int]int)
+	e.indexTransactions()
 
 	// Phân loại và xây dựng ItemTransactionMap
 	for _, transaction := range e.Transactions {
@@ -106,32 +374,67 @@ func (e *EMHUN) ClassifyItems() {
 
 			// Phân loại item theo utility
 			if utility > 0 {
-				hasPositive[item] = true
+				e.positiveCount[item]++
 			} else if utility < 0 {
-				hasNegative[item] = true
+				e.negativeCount[item]++
+			} else {
+				e.zeroCount[item]++
 			}
 
 			// Thêm giao dịch vào ItemTransactionMap cho item này
-			e.ItemTransactionMap[item] = append(e.ItemTransactionMap[item], transaction)
+			e.addItemToTransactionMap(item, transaction)
 		}
 	}
 
 	// Tạo các map Rho, Delta, Eta và phân loại các item vào từng nhóm
-	for item := range e.unionKeys(hasPositive, hasNegative) {
-		positive := hasPositive[item]
-		negative := hasNegative[item]
-
-		if positive && !negative {
-			e.Rho[item] = true
-		} else if positive && negative {
-			e.Delta[item] = true
-		} else if negative && !positive {
-			e.Eta[item] = true
+	for item := range e.ItemTransactionMap {
+		e.classifyItem(item)
+	}
+}
+
+// indexTransactions gán TID cho các giao dịch chưa có (TID = 0) và lập chỉ mục TID → giao dịch
+func (e *EMHUN) indexTransactions() {
+	e.transactionsByID = make(map[int]*models.Transaction, len(e.Transactions))
+	for _, transaction := range e.Transactions {
+		if transaction.TID > e.nextTID {
+			e.nextTID = transaction.TID
 		}
 	}
+	for _, transaction := range e.Transactions {
+		if transaction.TID == 0 {
+			e.nextTID++
+			transaction.TID = e.nextTID
+		}
+		e.transactionsByID[transaction.TID] = transaction
+	}
+}
+
+// classifyItem xếp item vào đúng một trong ρ, δ, η dựa trên số lần xuất hiện dương/âm hiện tại.
+// Item chỉ có utility 0 không làm giảm utility của itemset nào nên được xếp vào ρ.
+func (e *EMHUN) classifyItem(item int) {
+	positive := e.positiveCount[item] > 0
+	negative := e.negativeCount[item] > 0
+	zeroOnly := !positive && !negative && e.zeroCount[item] > 0
+
+	delete(e.Rho, item)
+	delete(e.Delta, item)
+	delete(e.Eta, item)
+
+	if (positive && !negative) || zeroOnly {
+		e.Rho[item] = true
+	} else if positive && negative {
+		e.Delta[item] = true
+	} else if negative && !positive {
+		e.Eta[item] = true
+	}
 }
 
 func (e *EMHUN) printClassification() {
+	e.Logger.Logf(logger.Summary, "After classify: |ρ| = %d, |δ| = %d, |η| = %d\n", len(e.Rho), len(e.Delta), len(e.Eta))
+	if !e.Logger.Enabled(logger.Node) {
+		return
+	}
+
 	rhoItems := e.keys(e.Rho)
 	deltaItems := e.keys(e.Delta)
 	etaItems := e.keys(e.Eta)
@@ -140,39 +443,52 @@ func (e *EMHUN) printClassification() {
 	sort.Ints(deltaItems)
 	sort.Ints(etaItems)
 
-	// fmt.Println("Items with positive utility only (ρ):", rhoItems)
-	// fmt.Println("Items with both positive and negative utility (δ):", deltaItems)
-	// fmt.Println("Items with negative utility only (η):", etaItems)
+	e.Logger.Logf(logger.Node, "Items with positive utility only, or only zero utility (ρ): %v\n", rhoItems)
+	e.Logger.Logf(logger.Node, "Items with both positive and negative utility (δ): %v\n", deltaItems)
+	e.Logger.Logf(logger.Node, "Items with negative utility only (η): %v\n", etaItems)
 }
 
-func (e *EMHUN) getSecondaryItems(combinedSet map[int]bool, utilityArray *models.UtilityArray, minU float64) []int {
+func (e *EMHUN) getSecondaryItems(combinedSet map[int]bool, rtwu map[int]float64, minU float64) []int {
 	var secondary []int
 	for item := range combinedSet {
-		rlu := utilityArray.GetRTWU(item)
+		rlu := rtwu[item]
 		if rlu >= minU {
 			secondary = append(secondary, item)
 		}
 	}
 	sort.Ints(secondary)
-	fmt.Printf("Secondary(X) items: %v\n", secondary)
+	e.Logger.Logf(logger.Summary, "Secondary(X): %d items\n", len(secondary))
+	e.Logger.Logf(logger.Node, "Secondary(X) items: %v\n", secondary)
 	return secondary
 }
 
-func (e *EMHUN) sortItems(items []int) []int {
-	sort.Slice(items, func(i, j int) bool {
-		typeOrderI := e.getTypeOrder(items[i])
-		typeOrderJ := e.getTypeOrder(items[j])
+// itemOrder là khóa sắp xếp của một item theo thứ tự xử lý: nhóm ρ/δ/η, RTWU rồi mã item
+type itemOrder struct {
+	item      int
+	typeOrder int
+	rtwu      float64
+}
 
-		if typeOrderI != typeOrderJ {
-			return typeOrderI < typeOrderJ
+func (e *EMHUN) sortItems(items []int) []int {
+	// Khóa của mỗi item được tính một lần thay vì tra các map ở mỗi phép so sánh
+	keys := make([]itemOrder, len(items))
+	for i, item := range items {
+		keys[i] = itemOrder{item: item, typeOrder: e.getTypeOrder(item), rtwu: e.RTWU[item]}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].typeOrder != keys[j].typeOrder {
+			return keys[i].typeOrder < keys[j].typeOrder
 		}
-
-		rtwuI := e.UtilityArray.GetRTWU(items[i])
-		rtwuJ := e.UtilityArray.GetRTWU(items[j])
-
-		return rtwuI < rtwuJ
+		if keys[i].rtwu != keys[j].rtwu {
+			return keys[i].rtwu < keys[j].rtwu
+		}
+		// Cùng RTWU thì so theo mã item để thứ tự xử lý luôn xác định
+		return keys[i].item < keys[j].item
 	})
 
+	for i, key := range keys {
+		items[i] = key.item
+	}
 	return items
 }
 
@@ -229,7 +545,7 @@ func (e *EMHUN) RemoveUnwantedItemsInTransactionsAndMap() {
 	// Xóa các mục trong `ItemTransactionMap` nếu danh sách giao dịch trống
 	for item, transactions := range e.ItemTransactionMap {
 		if len(transactions) == 0 {
-			delete(e.ItemTransactionMap, item)
+			e.deleteItemTransactions(item)
 		}
 	}
 }
@@ -242,7 +558,7 @@ func (e *EMHUN) RemoveUnwantedItemsInTransactionMap() {
 	for item, transactions := range e.ItemTransactionMap {
 		// Nếu mục không nằm trong hợp của `SortedSecondary` và `SortedEta`, xóa toàn bộ giao dịch
 		if !secondaryItemsMap[item] && !etaItemsMap[item] {
-			delete(e.ItemTransactionMap, item)
+			e.deleteItemTransactions(item)
 			continue
 		}
 
@@ -265,157 +581,49 @@ func containsAny(items []int, secondaryItemsMap, etaItemsMap map[int]bool) bool
 	return false
 }
 
-// Hàm bổ sung để loại bỏ `item` khỏi một giao dịch cụ thể trong `ItemTransactionMap`
-func (e *EMHUN) removeItemFromTransactionMap(item int, transaction *models.Transaction) {
-	transactions, exists := e.ItemTransactionMap[item]
-	if exists {
-		for i, t := range transactions {
-			if t == transaction {
-				// Xóa transaction khỏi danh sách
-				e.ItemTransactionMap[item] = append(transactions[:i], transactions[i+1:]...)
-				break
-			}
-		}
-	}
-}
-
-//Hàm cũ
-// func (e *EMHUN) SortItemsInTransactions() {
-// 	for _, transaction := range e.Transactions {
-// 		itemUtilityMap := make(map[int]float64) // Sửa giá trị map từ int thành float64
-// 		for i, item := range transaction.Items {
-// 			itemUtilityMap[item] = transaction.Utilities[i]
-// 		}
-
-// 		var positiveItems []int
-// 		var hybridItems []int
-// 		var negativeItems []int
-
-// 		for _, item := range transaction.Items {
-// 			if e.Rho[item] {
-// 				positiveItems = append(positiveItems, item)
-// 			} else if e.Delta[item] {
-// 				hybridItems = append(hybridItems, item)
-// 			} else if e.Eta[item] {
-// 				negativeItems = append(negativeItems, item)
-// 			}
-// 		}
-
-// 		positiveItems = e.sortItemsByRTWU(positiveItems)
-// 		hybridItems = e.sortItemsByRTWU(hybridItems)
-// 		negativeItems = e.sortItemsByRTWU(negativeItems)
-
-// 		sortedItems := append(append(positiveItems, hybridItems...), negativeItems...)
-
-// 		var sortedUtilities []float64 // Sửa từ int thành float64
-// 		for _, item := range sortedItems {
-// 			sortedUtilities = append(sortedUtilities, itemUtilityMap[item])
-// 		}
-
-//			transaction.Items = sortedItems
-//			transaction.Utilities = sortedUtilities
-//		}
-//	}
-//
-// Hàm sửa
-func (e *EMHUN) SortItemsInTransactionsAndMap() {
-
-	// Sắp xếp các phần tử trong `ItemTransactionMap`
-	for _, transactions := range e.ItemTransactionMap {
-		for _, transaction := range transactions {
-			e.sortTransactionItems(transaction)
-		}
-	}
-}
-
-// Hàm bổ trợ để sắp xếp các phần tử trong một giao dịch cụ thể
-func (e *EMHUN) sortTransactionItems(transaction *models.Transaction) {
-	// Tạo map lưu `utility` của từng item trong giao dịch
-	itemUtilityMap := make(map[int]float64)
-	for i, item := range transaction.Items {
-		itemUtilityMap[item] = transaction.Utilities[i]
+// addItemToTransactionMap thêm giao dịch vào danh sách giao dịch của item và ghi lại vị trí của nó theo TID
+func (e *EMHUN) addItemToTransactionMap(item int, transaction *models.Transaction) {
+	positions := e.itemPositions[item]
+	if positions == nil {
+		positions = make(map[int]int)
+		e.itemPositions[item] = positions
 	}
-
-	// Phân loại các item vào các nhóm `Rho`, `Delta`, và `Eta`
-	var positiveItems []int
-	var hybridItems []int
-	var negativeItems []int
-
-	for _, item := range transaction.Items {
-		if e.Rho[item] {
-			positiveItems = append(positiveItems, item)
-		} else if e.Delta[item] {
-			hybridItems = append(hybridItems, item)
-		} else if e.Eta[item] {
-			negativeItems = append(negativeItems, item)
-		}
+	// Item lặp trong một giao dịch chỉ đưa giao dịch vào danh sách một lần
+	if i, exists := positions[transaction.TID]; exists && e.ItemTransactionMap[item][i] == transaction {
+		return
 	}
-
-	// Sắp xếp từng nhóm item theo `RTWU`
-	positiveItems = e.sortItemsByRTWU(positiveItems)
-	hybridItems = e.sortItemsByRTWU(hybridItems)
-	negativeItems = e.sortItemsByRTWU(negativeItems)
-
-	// Kết hợp các nhóm lại theo thứ tự: `positiveItems`, `hybridItems`, `negativeItems`
-	sortedItems := append(append(positiveItems, hybridItems...), negativeItems...)
-
-	// Cập nhật lại `Items` và `Utilities` của giao dịch sau khi sắp xếp
-	var sortedUtilities []float64
-	for _, item := range sortedItems {
-		sortedUtilities = append(sortedUtilities, itemUtilityMap[item])
-	}
-
-	transaction.Items = sortedItems
-	transaction.Utilities = sortedUtilities
+	positions[transaction.TID] = len(e.ItemTransactionMap[item])
+	e.ItemTransactionMap[item] = append(e.ItemTransactionMap[item], transaction)
 }
 
-// func (e *EMHUN) SortTransactionsByTWU() {
-// 	fmt.Println("\nSorting transactions by total RTWU of items...")
-
-// 	sort.Slice(e.Transactions, func(i, j int) bool {
-// 		tuI := utility.CalculateTransactionUtility(e.Transactions[i])
-// 		tuJ := utility.CalculateTransactionUtility(e.Transactions[j])
-
-// 		// Sắp xếp tăng dần theo tổng RLU
-// 		return tuI < tuJ
-// 	})
-
-// }
-// Ham moi
-func (e *EMHUN) SortTransactionsByTWU() {
-	fmt.Println("\nSorting transactions by total RTWU of items...")
-
-	// sort.Slice(e.Transactions, func(i, j int) bool {
-	// 	tuI := utility.CalculateTransactionUtility(e.Transactions[i])
-	// 	tuJ := utility.CalculateTransactionUtility(e.Transactions[j])
-
-	// 	// Sắp xếp tăng dần theo tổng RLU
-	// 	return tuI < tuJ
-	// })
-
-	// Sắp xếp các giao dịch trong `ItemTransactionMap` theo tổng RLU
-	for _, transactions := range e.ItemTransactionMap {
-		sort.Slice(transactions, func(i, j int) bool {
-			tuI := utility.CalculateTransactionUtility(transactions[i])
-			tuJ := utility.CalculateTransactionUtility(transactions[j])
-
-			// Sắp xếp tăng dần theo tổng RLU
-			return tuI < tuJ
-		})
+// Hàm bổ sung để loại bỏ `item` khỏi một giao dịch cụ thể trong `ItemTransactionMap`.
+// Vị trí của giao dịch được tra theo TID; giao dịch cuối danh sách được chuyển vào chỗ trống.
+func (e *EMHUN) removeItemFromTransactionMap(item int, transaction *models.Transaction) {
+	positions := e.itemPositions[item]
+	i, exists := positions[transaction.TID]
+	if !exists {
+		return
 	}
+	transactions := e.ItemTransactionMap[item]
+	last := len(transactions) - 1
+	transactions[i] = transactions[last]
+	positions[transactions[i].TID] = i
+	transactions[last] = nil
+	e.ItemTransactionMap[item] = transactions[:last]
+	delete(positions, transaction.TID)
 }
 
-func (e *EMHUN) sortItemsByRTWU(items []int) []int {
-	sort.Slice(items, func(i, j int) bool {
-		return e.UtilityArray.GetRTWU(items[i]) < e.UtilityArray.GetRTWU(items[j])
-	})
-	return items
+// deleteItemTransactions xóa danh sách giao dịch của item khỏi ItemTransactionMap
+func (e *EMHUN) deleteItemTransactions(item int) {
+	delete(e.ItemTransactionMap, item)
+	delete(e.itemPositions, item)
 }
 
 // Giữ nguyen
 func (e *EMHUN) identifyPrimaryItems() {
-	for _, item := range e.SortedSecondary {
-		if e.UtilityArray.GetRSU(item) >= e.MinUtility {
+	// Chỉ số của SortedSecondary[i] sau khi đánh lại số là i
+	for index, item := range e.SortedSecondary {
+		if e.UtilityArray.GetRSU(index) >= e.MinUtility {
 			e.PrimaryItems = append(e.PrimaryItems, item)
 		}
 	}
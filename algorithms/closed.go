@@ -0,0 +1,76 @@
+package algorithms
+
+import (
+	"EMHUNer/models"
+	"sort"
+)
+
+// occurrences tóm tắt các giao dịch chứa một itemset: số giao dịch và utility nhỏ nhất/lớn nhất
+// của itemset trong một giao dịch
+type occurrences struct {
+	support    int
+	minUtility float64
+	maxUtility float64
+}
+
+func (o *occurrences) add(utility float64) {
+	if o.support == 0 || utility < o.minUtility {
+		o.minUtility = utility
+	}
+	if o.support == 0 || utility > o.maxUtility {
+		o.maxUtility = utility
+	}
+	o.support++
+}
+
+// collectTIDs trả về TID tăng dần của các giao dịch, giữ tối đa limit TID đầu tiên khi limit > 0,
+// cùng cờ cho biết danh sách đã bị cắt bớt
+func collectTIDs(projection models.Projection, limit int) ([]int, bool) {
+	tids := make([]int, len(projection))
+	for i, entry := range projection {
+		tids[i] = entry.Transaction.TID
+	}
+	sort.Ints(tids)
+	if limit > 0 && len(tids) > limit {
+		// Sao chép để danh sách đầy đủ được giải phóng
+		return append([]int(nil), tids[:limit]...), true
+	}
+	return tids, false
+}
+
+// originalTransactions trả về các giao dịch gốc (theo TID) của cơ sở dữ liệu chiếu. Giao dịch của tìm kiếm
+// chỉ chứa các item còn lại sau khi lọc, trong khi tính đóng phải xét mọi item của giao dịch.
+func (s *SearchAlgorithms) originalTransactions(projection models.Projection) []*models.Transaction {
+	if s.transactionsByID == nil {
+		return projection.Transactions()
+	}
+	originals := make([]*models.Transaction, len(projection))
+	for i, entry := range projection {
+		originals[i] = s.transactionsByID[entry.Transaction.TID]
+	}
+	return originals
+}
+
+// isClosed cho biết itemset có đóng hay không: không có item nào ngoài itemset xuất hiện
+// trong mọi giao dịch chứa nó. Itemset và các giao dịch phải cùng dùng mã item gốc để các item η
+// và các item đã bị loại khỏi Secondary vẫn được xét.
+func isClosed(itemset []int, transactions []*models.Transaction) bool {
+	if len(transactions) == 0 {
+		return true
+	}
+
+	inItemset := convertSliceToMap(itemset)
+	counts := make(map[int]int)
+	for _, transaction := range transactions {
+		for _, item := range transaction.Items {
+			if inItemset[item] {
+				continue
+			}
+			counts[item]++
+			if counts[item] == len(transactions) {
+				return false
+			}
+		}
+	}
+	return true
+}
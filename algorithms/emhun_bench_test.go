@@ -0,0 +1,343 @@
+package algorithms
+
+import (
+	"EMHUNer/dataset"
+	"EMHUNer/models"
+	"EMHUNer/utility"
+	"math"
+	"sort"
+	"testing"
+)
+
+// benchmarkDatasets là các bộ dữ liệu thật dùng để đo thời gian khai thác, với ngưỡng tương đối
+// đủ thấp để phần tìm kiếm chiếm đa số thời gian chạy
+var benchmarkDatasets = []struct {
+	name  string
+	file  string
+	ratio float64
+}{
+	{"BMS", "../data/BMS.txt", 0.03},
+	{"foodmart", "../data/foodmart_dynamic.txt", 0.002},
+}
+
+func loadBenchmarkDataset(b *testing.B, file string) []*models.Transaction {
+	b.Helper()
+	// foodmart_dynamic.txt có 37 giao dịch lặp item, được gộp như TestFoodmartMatchesPublishedResults
+	transactions, _, err := dataset.Load(file, dataset.Options{MergeDuplicates: true})
+	if err != nil {
+		b.Skipf("cannot load %s: %v", file, err)
+	}
+	return transactions
+}
+
+// BenchmarkEMHUN so sánh EMHUN (item đánh lại số, cận trong mảng) với mapEMHUN, cách khai thác trước khi
+// đánh lại số item, trên cùng bộ dữ liệu và ngưỡng
+func BenchmarkEMHUN(b *testing.B) {
+	for _, bench := range benchmarkDatasets {
+		transactions := loadBenchmarkDataset(b, bench.file)
+		want := NewEMHUNWithRatio(cloneTransactions(transactions), bench.ratio).Run().HighUtilityItemsets.Len()
+		if got := mapEMHUN(cloneTransactions(transactions), bench.ratio); got != want {
+			b.Fatalf("%s: map-backed baseline finds %d HUIs, EMHUN %d", bench.name, got, want)
+		}
+
+		b.Run(bench.name+"/map", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				clones := cloneTransactions(transactions)
+				b.StartTimer()
+				mapEMHUN(clones, bench.ratio)
+			}
+		})
+		b.Run(bench.name+"/dense", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				emhun := NewEMHUNWithRatio(cloneTransactions(transactions), bench.ratio)
+				b.StartTimer()
+				emhun.Run()
+			}
+		})
+	}
+}
+
+// BenchmarkAppendTransactions đo một lần cập nhật tăng dần thêm 10 giao dịch (lặp lại 10 giao dịch đầu
+// của bộ dữ liệu) vào cơ sở dữ liệu đã được khai thác bằng Run
+func BenchmarkAppendTransactions(b *testing.B) {
+	for _, bench := range benchmarkDatasets {
+		transactions := loadBenchmarkDataset(b, bench.file)
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				emhun := NewEMHUNWithRatio(cloneTransactions(transactions), bench.ratio)
+				emhun.Run()
+				batch := cloneTransactions(transactions[:10])
+				for _, transaction := range batch {
+					transaction.TID = 0
+				}
+				b.StartTimer()
+				if _, err := emhun.AppendTransactions(batch); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkStreamMiner đo một lần trượt cửa sổ 10 giao dịch trên cửa sổ gồm nửa đầu của bộ dữ liệu
+func BenchmarkStreamMiner(b *testing.B) {
+	for _, bench := range benchmarkDatasets {
+		transactions := loadBenchmarkDataset(b, bench.file)
+		window := len(transactions) / 2
+		b.Run(bench.name, func(b *testing.B) {
+			b.StopTimer()
+			stream := NewStreamMinerWithRatio(window, bench.ratio)
+			if _, err := stream.Push(cloneTransactions(transactions[:window])); err != nil {
+				b.Fatal(err)
+			}
+			next := window
+			for i := 0; i < b.N; i++ {
+				if next+10 > len(transactions) {
+					next = 0
+				}
+				batch := cloneTransactions(transactions[next : next+10])
+				for _, transaction := range batch {
+					transaction.TID = 0
+				}
+				next += 10
+				b.StartTimer()
+				if _, err := stream.Push(batch); err != nil {
+					b.Fatal(err)
+				}
+				b.StopTimer()
+			}
+		})
+	}
+}
+
+// mapEMHUN là cách khai thác trước khi đánh lại số item, chỉ được giữ lại để so sánh trong BenchmarkEMHUN.
+// Item giữ mã gốc và mọi item đã phân loại; mỗi giao dịch được sắp lại theo thứ tự xử lý mỗi lần gặp
+// trong danh sách của một item. Mỗi nút chiếu bằng copyProjection và tính RSU, rồi RLU, của mọi item
+// thứ cấp vào map theo mã item, mỗi item một lượt duyệt cơ sở dữ liệu chiếu. Trả về số HUI.
+func mapEMHUN(transactions []*models.Transaction, ratio float64) int {
+	e := NewEMHUNWithRatio(transactions, ratio)
+	e.ResolveMinUtility()
+	e.ClassifyItems()
+	combinedSet := e.unionKeys(e.Rho, e.Delta)
+	utility.CalculateRTWUForAllItems(e.ItemTransactionMap, combinedSet, e.Eta, e.RTWU)
+	secondary := e.sortItems(e.getSecondaryItems(combinedSet, e.RTWU, e.MinUtility))
+	eta := e.sortItems(e.keys(e.Eta))
+
+	rank := make(map[int]int)
+	for i, item := range e.sortItems(e.keys(e.unionKeys(combinedSet, e.Eta))) {
+		rank[item] = i
+	}
+	copies := make(map[*models.Transaction]*models.Transaction)
+	itemTransactionMap := make(map[int][]*models.Transaction)
+	for _, item := range append(append([]int(nil), secondary...), eta...) {
+		for _, transaction := range e.ItemTransactionMap[item] {
+			if copies[transaction] == nil {
+				copies[transaction] = models.NewTransaction(append([]int(nil), transaction.Items...),
+					append([]float64(nil), transaction.Utilities...), transaction.TransactionUtility)
+			}
+			sort.Sort(byRank{copies[transaction], rank})
+			itemTransactionMap[item] = append(itemTransactionMap[item], copies[transaction])
+		}
+	}
+
+	m := &mapSearch{minU: e.MinUtility, eta: eta}
+	rsu := make(map[int]float64)
+	for _, item := range secondary {
+		for _, transaction := range itemTransactionMap[item] {
+			index := indexOf(transaction.Items, item)
+			rsu[item] += math.Max(0, transaction.Utilities[index]+utility.CalculateRemainingUtility(transaction, index+1))
+		}
+	}
+	for _, item := range secondary {
+		if rsu[item] >= m.minU {
+			m.searchItem(nil, item, itemTransactionMap, secondary)
+		}
+	}
+	return m.huis
+}
+
+// byRank sắp các item của giao dịch (cùng utility tương ứng) theo thứ tự xử lý
+type byRank struct {
+	*models.Transaction
+	rank map[int]int
+}
+
+func (t byRank) Len() int           { return len(t.Items) }
+func (t byRank) Less(i, j int) bool { return t.rank[t.Items[i]] < t.rank[t.Items[j]] }
+func (t byRank) Swap(i, j int) {
+	t.Items[i], t.Items[j] = t.Items[j], t.Items[i]
+	t.Utilities[i], t.Utilities[j] = t.Utilities[j], t.Utilities[i]
+}
+
+type mapSearch struct {
+	minU float64
+	eta  []int
+	huis int
+}
+
+// searchItem xử lý nhánh X ∪ {item} với các item thứ cấp secondary
+func (m *mapSearch) searchItem(x []int, item int, itemTransactionMap map[int][]*models.Transaction, secondary []int) {
+	beta := append(append([]int(nil), x...), item)
+	projected, utilityBeta := copyProjection(itemTransactionMap, beta)
+	if utilityBeta >= m.minU {
+		m.huis++
+	}
+	// copyProjection chỉ giữ danh sách của item đầu tiên của beta
+	positive := 0.0
+	for _, transaction := range projected[beta[0]] {
+		positive += math.Max(0, transaction.TransactionUtility)
+	}
+	if positive >= m.minU {
+		m.searchN(beta, projected, m.eta)
+	}
+
+	rsu := mapRSU(projected, beta, secondary)
+	rlu := mapRLU(projected, beta, secondary)
+	var primary, next []int
+	for _, candidate := range secondary[indexOf(secondary, item)+1:] {
+		if rsu[candidate] >= m.minU {
+			primary = append(primary, candidate)
+		}
+		if rlu[candidate] >= m.minU {
+			next = append(next, candidate)
+		}
+	}
+	for _, candidate := range primary {
+		m.searchItem(beta, candidate, projected, next)
+	}
+}
+
+// searchN mở rộng beta bằng các item η trong eta
+func (m *mapSearch) searchN(beta []int, itemTransactionMap map[int][]*models.Transaction, eta []int) {
+	for i, item := range eta {
+		betaNew := append(append([]int(nil), beta...), item)
+		projected, utilityBetaNew := copyProjection(itemTransactionMap, betaNew)
+		if utilityBetaNew >= m.minU {
+			m.huis++
+		}
+		rsu := mapRSU(projected, betaNew, eta)
+		var primary []int
+		for _, candidate := range eta[i+1:] {
+			if rsu[candidate] >= m.minU {
+				primary = append(primary, candidate)
+			}
+		}
+		m.searchN(betaNew, projected, primary)
+	}
+}
+
+// mapRSU tính RSU của X ∪ {z} cho từng z thuộc candidates, mỗi z một lượt duyệt cơ sở dữ liệu chiếu
+func mapRSU(projected map[int][]*models.Transaction, x []int, candidates []int) map[int]float64 {
+	rsu := make(map[int]float64)
+	for _, candidate := range candidates {
+		for _, transactions := range projected {
+			for _, transaction := range transactions {
+				index := indexOf(transaction.Items, candidate)
+				if index == -1 || !containsAllItems(transaction.Items, x) {
+					continue
+				}
+				utilityX, _ := mapUtility(transaction, x)
+				remaining := utility.CalculateRemainingUtility(transaction, index+1)
+				rsu[candidate] += math.Max(0, utilityX+transaction.Utilities[index]+remaining)
+			}
+		}
+	}
+	return rsu
+}
+
+// mapRLU tính RLU của X ∪ {z} cho từng z thuộc candidates, mỗi z một lượt duyệt cơ sở dữ liệu chiếu
+func mapRLU(projected map[int][]*models.Transaction, x []int, candidates []int) map[int]float64 {
+	rlu := make(map[int]float64)
+	for _, candidate := range candidates {
+		for _, transactions := range projected {
+			for _, transaction := range transactions {
+				if indexOf(transaction.Items, candidate) == -1 || !containsAllItems(transaction.Items, x) {
+					continue
+				}
+				utilityX, last := mapUtility(transaction, x)
+				rlu[candidate] += math.Max(0, utilityX+utility.CalculateRemainingUtility(transaction, last+1))
+			}
+		}
+	}
+	return rlu
+}
+
+// mapUtility trả về utility của X trong giao dịch và vị trí của item đứng sau cùng của X
+func mapUtility(transaction *models.Transaction, x []int) (float64, int) {
+	utilityX, last := 0.0, -1
+	for _, item := range x {
+		position := indexOf(transaction.Items, item)
+		utilityX += transaction.Utilities[position]
+		if position > last {
+			last = position
+		}
+	}
+	return utilityX, last
+}
+
+// copyProjection là cách chiếu trước khi có models.Projection: sao chép item và utility của mọi giao dịch
+// chứa items thành giao dịch mới trong một map mới. Chỉ được giữ lại để so sánh trong BenchmarkProjection.
+func copyProjection(itemTransactionMap map[int][]*models.Transaction, items []int) (map[int][]*models.Transaction, float64) {
+	projectedItemTransactionMap := make(map[int][]*models.Transaction)
+	totalUtility := 0.0
+	for _, item := range items {
+		for _, transaction := range itemTransactionMap[item] {
+			if !containsAllItems(transaction.Items, items) {
+				continue
+			}
+			projectedItems := append([]int(nil), transaction.Items...)
+			projectedUtilities := append([]float64(nil), transaction.Utilities...)
+			transactionUtility := 0.0
+			for _, item := range items {
+				transactionUtility += transaction.Utilities[indexOf(transaction.Items, item)]
+			}
+			totalUtility += transactionUtility
+
+			projectedTransaction := models.NewTransaction(projectedItems, projectedUtilities, transactionUtility)
+			projectedTransaction.TID = transaction.TID
+			projectedItemTransactionMap[item] = append(projectedItemTransactionMap[item], projectedTransaction)
+		}
+	}
+	return projectedItemTransactionMap, totalUtility
+}
+
+// BenchmarkProjection so sánh việc chiếu bằng cách sao chép giao dịch với chiếu bằng vị trí trong giao dịch
+// trên cùng một khối việc: chiếu theo từng item Primary rồi theo từng item Secondary đứng sau nó
+func BenchmarkProjection(b *testing.B) {
+	for _, bench := range benchmarkDatasets {
+		transactions := loadBenchmarkDataset(b, bench.file)
+		emhun := NewEMHUNWithRatio(transactions, bench.ratio)
+		emhun.Run()
+		s := emhun.SearchAlgorithms
+		primary := emhun.remap.denseItems(emhun.PrimaryItems)
+		secondary := span(0, len(emhun.SortedSecondary))
+
+		b.Run(bench.name+"/copy", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, item := range primary {
+					projected, _ := copyProjection(emhun.searchMap, []int{item})
+					for _, next := range secondary[item+1:] {
+						copyProjection(projected, []int{item, next})
+					}
+				}
+			}
+		})
+		b.Run(bench.name+"/offset", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, item := range primary {
+					projected, _, _ := s.project(models.NewProjection(emhun.searchMap[item]), item)
+					for _, next := range secondary[item+1:] {
+						s.project(projected, next)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,735 @@
+package algorithms
+
+import (
+	"EMHUNer/dataset"
+	"EMHUNer/logger"
+	"EMHUNer/models"
+	"EMHUNer/utility"
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const randomSeeds = 300
+
+// randomTransaction sinh một giao dịch trên các item 1..10: item 9 và 10 luôn âm (η),
+// item chia hết cho 3 có thể âm hoặc dương (δ), các item còn lại luôn dương (ρ). Utility có thể
+// bằng 0, và item 7 luôn có utility 0.
+func randomTransaction(r *rand.Rand) *models.Transaction {
+	length := 1 + r.Intn(6)
+	var items []int
+	var utilities []float64
+	transactionUtility := 0.0
+	for _, index := range r.Perm(10)[:length] {
+		item := index + 1
+		utility := float64(r.Intn(11))
+		if item == 7 {
+			utility = 0
+		}
+		if item >= 9 || (item%3 == 0 && r.Intn(2) == 0) {
+			utility = -utility
+		}
+		items = append(items, item)
+		utilities = append(utilities, utility)
+		transactionUtility += utility
+	}
+	return models.NewTransaction(items, utilities, transactionUtility)
+}
+
+func randomDatabase(r *rand.Rand, n int) []*models.Transaction {
+	transactions := make([]*models.Transaction, n)
+	for i := range transactions {
+		transactions[i] = randomTransaction(r)
+	}
+	return transactions
+}
+
+// cloneTransactions sao chép sâu các giao dịch vì Run gán TID cho các giao dịch chưa có
+func cloneTransactions(transactions []*models.Transaction) []*models.Transaction {
+	clones := make([]*models.Transaction, len(transactions))
+	for i, t := range transactions {
+		clones[i] = models.NewTransaction(append([]int(nil), t.Items...), append([]float64(nil), t.Utilities...), t.TransactionUtility)
+		clones[i].TID = t.TID
+	}
+	return clones
+}
+
+func reference(t *testing.T, transactions []*models.Transaction, minUtility float64) []*models.HighUtilityItemset {
+	t.Helper()
+	huis, err := ReferenceHUIs(transactions, minUtility)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return huis
+}
+
+func checkAgainstReference(t *testing.T, name string, got, want []*models.HighUtilityItemset) {
+	t.Helper()
+	if diff := DiffResults(got, want); !diff.Empty() {
+		t.Errorf("%s: result differs from the reference miner:\n%s", name, diff)
+	}
+}
+
+// checkSearchDatabase so cơ sở dữ liệu tìm kiếm và RSU hiện tại với bản dựng lại từ ItemTransactionMap
+// theo cùng cách đánh số
+func checkSearchDatabase(t *testing.T, name string, emhun *EMHUN) {
+	t.Helper()
+	want, _ := emhun.remap.itemTransactionMap(emhun.ItemTransactionMap)
+	rsu := models.NewUtilityArray(len(emhun.remap.original))
+	utility.CalculateRSUForAllItems(want, span(0, len(emhun.SortedSecondary)), rsu)
+
+	tids := func(transactions []*models.Transaction) []int {
+		var tids []int
+		for _, transaction := range transactions {
+			tids = append(tids, transaction.TID)
+		}
+		sort.Ints(tids)
+		return tids
+	}
+	for index := range emhun.remap.original {
+		got := emhun.searchMap[index]
+		if fmt.Sprint(tids(got)) != fmt.Sprint(tids(want[index])) {
+			t.Errorf("%s: item %d: transactions %v, want %v", name, index, tids(got), tids(want[index]))
+		}
+		if !sort.SliceIsSorted(got, func(i, j int) bool { return got[i].TransactionUtility < got[j].TransactionUtility }) {
+			t.Errorf("%s: item %d: transactions are not sorted by utility", name, index)
+		}
+		if index < len(emhun.SortedSecondary) && emhun.UtilityArray.GetRSU(index) != rsu.GetRSU(index) {
+			t.Errorf("%s: item %d: RSU %.2f, want %.2f", name, index, emhun.UtilityArray.GetRSU(index), rsu.GetRSU(index))
+		}
+	}
+}
+
+func readTable3(t *testing.T) []*models.Transaction {
+	t.Helper()
+	transactions, _, err := dataset.Load("../data/table3.txt", dataset.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transactions
+}
+
+func TestReferenceHUIsTable3(t *testing.T) {
+	huis := reference(t, readTable3(t), 30)
+	want := map[string]float64{"[3 4 5]": 37, "[4 5]": 37, "[3 4]": 31, "[2 4 5]": 31}
+	if len(huis) != len(want) {
+		t.Fatalf("got %d HUIs, want %d: %v", len(huis), len(want), huis)
+	}
+	for _, hui := range huis {
+		if u, ok := want[itemsetKey(hui.Itemset)]; !ok || u != hui.Utility {
+			t.Errorf("unexpected HUI %v with utility %.2f", hui.Itemset, hui.Utility)
+		}
+	}
+}
+
+func TestEMHUNMatchesReferenceOnTable3(t *testing.T) {
+	transactions := readTable3(t)
+	for _, minUtility := range []float64{1, 5, 10, 15, 20, 25, 30, 40} {
+		want := reference(t, transactions, minUtility)
+		got := NewEMHUN(cloneTransactions(transactions), minUtility).Run().HighUtilityItemsets.Items()
+		checkAgainstReference(t, "table3", got, want)
+	}
+}
+
+func TestEMHUNMatchesReferenceOnRandomDatabases(t *testing.T) {
+	for seed := int64(0); seed < randomSeeds; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		transactions := randomDatabase(r, 5+r.Intn(15))
+		minUtility := float64(5 + r.Intn(35))
+
+		want := reference(t, transactions, minUtility)
+		got := NewEMHUN(cloneTransactions(transactions), minUtility).Run().HighUtilityItemsets.Items()
+		checkAgainstReference(t, fmt.Sprintf("seed %d", seed), got, want)
+	}
+}
+
+func TestSparseItemIDsAreRemapped(t *testing.T) {
+	for seed := int64(0); seed < randomSeeds; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		transactions := randomDatabase(r, 5+r.Intn(15))
+		for _, transaction := range transactions {
+			for i := range transaction.Items {
+				transaction.Items[i] = transaction.Items[i]*100003 + 7
+			}
+		}
+		minUtility := float64(5 + r.Intn(35))
+
+		want := reference(t, transactions, minUtility)
+		input := cloneTransactions(transactions)
+		emhun := NewEMHUN(input, minUtility)
+		got := emhun.Run().HighUtilityItemsets.Items()
+		checkAgainstReference(t, fmt.Sprintf("seed %d", seed), got, want)
+
+		if emhun.UtilityArray.Len() != len(emhun.SortedSecondary)+len(emhun.SortedEta) {
+			t.Errorf("seed %d: UtilityArray has %d entries, want one per remaining item", seed, emhun.UtilityArray.Len())
+		}
+		for i, transaction := range input {
+			if fmt.Sprint(transaction.Items, transaction.Utilities) != fmt.Sprint(transactions[i].Items, transactions[i].Utilities) {
+				t.Fatalf("seed %d: Run modified transaction %d", seed, i)
+			}
+		}
+	}
+}
+
+func TestTopKMatchesReference(t *testing.T) {
+	for seed := int64(0); seed < randomSeeds; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		transactions := randomDatabase(r, 5+r.Intn(15))
+		k := 1 + r.Intn(10)
+
+		var want []float64
+		utilities := make(map[string]float64)
+		for _, hui := range reference(t, transactions, minPositiveUtility) {
+			want = append(want, hui.Utility)
+			utilities[itemsetKey(hui.Itemset)] = hui.Utility
+		}
+		sort.Sort(sort.Reverse(sort.Float64Slice(want)))
+		if len(want) > k {
+			want = want[:k]
+		}
+
+		result := NewEMHUNTopK(cloneTransactions(transactions), k).Run()
+		if len(result.HighUtilityItemsets.Items()) != len(want) {
+			t.Errorf("seed %d: got %d itemsets, want %d", seed, len(result.HighUtilityItemsets.Items()), len(want))
+			continue
+		}
+		// Các utility phải là k utility lớn nhất; khi có nhiều itemset cùng utility thứ k, itemset nào
+		// trong số đó được giữ cũng đúng, nhưng mỗi itemset phải là HUI của tham chiếu với đúng utility đó
+		seen := make(map[string]bool)
+		for i, hui := range result.HighUtilityItemsets.Items() {
+			if hui.Utility != want[i] {
+				t.Errorf("seed %d: itemset %d has utility %.2f, want %.2f", seed, i, hui.Utility, want[i])
+			}
+			key := itemsetKey(hui.Itemset)
+			if u, ok := utilities[key]; !ok || u != hui.Utility || seen[key] {
+				t.Errorf("seed %d: itemset %v with utility %.2f is not a distinct reference HUI (reference utility %.2f, found %v)",
+					seed, hui.Itemset, hui.Utility, u, ok)
+			}
+			seen[key] = true
+		}
+	}
+}
+
+func TestClosedMatchesReference(t *testing.T) {
+	for seed := int64(0); seed < randomSeeds; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		transactions := randomDatabase(r, 5+r.Intn(15))
+		minUtility := float64(5 + r.Intn(35))
+
+		// Itemset đóng khi không có item nào khác xuất hiện trong mọi giao dịch chứa nó
+		var want []*models.HighUtilityItemset
+		for _, hui := range reference(t, transactions, minUtility) {
+			var supporting []*models.Transaction
+			for _, transaction := range transactions {
+				if containsAllItems(transaction.Items, hui.Itemset) {
+					supporting = append(supporting, transaction)
+				}
+			}
+			if isClosed(hui.Itemset, supporting) {
+				want = append(want, hui)
+			}
+		}
+
+		emhun := NewEMHUN(cloneTransactions(transactions), minUtility)
+		emhun.Closed = true
+		got := emhun.Run().HighUtilityItemsets.Items()
+		checkAgainstReference(t, fmt.Sprintf("seed %d", seed), got, want)
+
+		supports := make(map[string]int)
+		for _, hui := range want {
+			supports[itemsetKey(hui.Itemset)] = hui.Support
+		}
+		for _, hui := range got {
+			if want, ok := supports[itemsetKey(hui.Itemset)]; ok && hui.Support != want {
+				t.Errorf("seed %d: %v has support %d, want %d", seed, hui.Itemset, hui.Support, want)
+			}
+		}
+	}
+}
+
+func TestMaximalMatchesReference(t *testing.T) {
+	for seed := int64(0); seed < randomSeeds; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		transactions := randomDatabase(r, 5+r.Intn(15))
+		minUtility := float64(5 + r.Intn(35))
+
+		all := reference(t, transactions, minUtility)
+		emhun := NewEMHUN(cloneTransactions(transactions), minUtility)
+		emhun.Maximal = true
+		if err := VerifyMaximal(emhun.Run().HighUtilityItemsets.Items(), all); err != nil {
+			t.Errorf("seed %d: %v", seed, err)
+		}
+	}
+}
+
+func TestIncrementalUpdatesMatchReference(t *testing.T) {
+	patched := 0
+	for seed := int64(0); seed < randomSeeds; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		transactions := randomDatabase(r, 5+r.Intn(15))
+		for i, transaction := range transactions {
+			transaction.TID = i + 1
+		}
+
+		emhun := NewEMHUN(cloneTransactions(transactions), float64(5+r.Intn(35)))
+		if seed%2 == 1 {
+			emhun = NewEMHUNWithRatio(cloneTransactions(transactions), 0.05+r.Float64()*0.3)
+		}
+		emhun.Run()
+
+		for step := 0; step < 3; step++ {
+			var update Update
+			deleted := make(map[int]bool)
+			for _, transaction := range transactions {
+				switch r.Intn(8) {
+				case 0:
+					update.Delete = append(update.Delete, transaction.TID)
+					deleted[transaction.TID] = true
+				case 1:
+					modified := randomTransaction(r)
+					modified.TID = transaction.TID
+					update.Modify = append(update.Modify, modified)
+				}
+			}
+			update.Append = randomDatabase(r, r.Intn(4))
+
+			// Cơ sở dữ liệu kỳ vọng sau khi áp dụng cùng các thay đổi
+			var expected []*models.Transaction
+			modified := make(map[int]*models.Transaction)
+			for _, transaction := range update.Modify {
+				modified[transaction.TID] = transaction
+			}
+			for _, transaction := range transactions {
+				if deleted[transaction.TID] {
+					continue
+				}
+				if replacement, ok := modified[transaction.TID]; ok {
+					transaction = replacement
+				}
+				expected = append(expected, transaction)
+			}
+			expected = append(expected, update.Append...)
+
+			update.Modify = cloneTransactions(update.Modify)
+			appended := cloneTransactions(update.Append)
+			update.Append = appended
+			remap := emhun.remap
+			delta, err := emhun.ApplyUpdate(update)
+			if err != nil {
+				t.Fatalf("seed %d step %d: %v", seed, step, err)
+			}
+			if emhun.remap == remap {
+				patched++
+			}
+			checkSearchDatabase(t, fmt.Sprintf("seed %d step %d", seed, step), emhun)
+			for i, transaction := range appended {
+				expected[len(expected)-len(appended)+i].TID = transaction.TID
+			}
+			transactions = expected
+
+			want := reference(t, transactions, emhun.MinUtility)
+			checkAgainstReference(t, fmt.Sprintf("seed %d step %d", seed, step), delta.Result.HighUtilityItemsets.Items(), want)
+		}
+	}
+	// Nhiều lần cập nhật không đổi tập item tìm kiếm nên không cần đánh lại số
+	if patched == 0 {
+		t.Error("no update kept the previous search database")
+	}
+}
+
+func TestStreamMinerMatchesReference(t *testing.T) {
+	for seed := int64(0); seed < randomSeeds; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		window := 3 + r.Intn(8)
+		slide := 1 + r.Intn(4)
+		minUtility := float64(5 + r.Intn(35))
+		stream := NewStreamMiner(window, minUtility)
+
+		var fed []*models.Transaction
+		for step := 0; step < 10; step++ {
+			batch := randomDatabase(r, slide)
+			fed = append(fed, batch...)
+			delta, err := stream.Push(cloneTransactions(batch))
+			if err != nil {
+				t.Fatalf("seed %d step %d: %v", seed, step, err)
+			}
+
+			current := fed
+			if len(current) > window {
+				current = current[len(current)-window:]
+			}
+			want := reference(t, current, minUtility)
+			checkAgainstReference(t, fmt.Sprintf("seed %d step %d", seed, step), delta.Result.HighUtilityItemsets.Items(), want)
+		}
+	}
+}
+
+func TestStreamMinerKeepsWindowAfterRejectedBatch(t *testing.T) {
+	for _, byBatch := range []bool{false, true} {
+		r := rand.New(rand.NewSource(1))
+		stream := NewStreamMiner(2, 10)
+		stream.WindowByBatch = byBatch
+
+		batches := make([][]*models.Transaction, 4)
+		for i := range batches {
+			batches[i] = randomDatabase(r, 2)
+		}
+		for _, batch := range batches[:2] {
+			if _, err := stream.Push(cloneTransactions(batch)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		size := stream.size
+
+		bad := cloneTransactions(batches[2])
+		bad[1].Utilities = bad[1].Utilities[1:]
+		if _, err := stream.Push(bad); err == nil {
+			t.Fatalf("by batch %v: batch with a missing utility accepted", byBatch)
+		}
+		if stream.size != size || len(stream.miner.Transactions) != size {
+			t.Fatalf("by batch %v: window holds %d transactions and the miner %d after the rejected batch, want %d",
+				byBatch, stream.size, len(stream.miner.Transactions), size)
+		}
+
+		delta, err := stream.Push(cloneTransactions(batches[3]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		current := append(append([]*models.Transaction(nil), batches[1]...), batches[3]...)
+		if !byBatch {
+			current = batches[3]
+		}
+		if len(stream.miner.Transactions) != len(current) {
+			t.Errorf("by batch %v: miner holds %d transactions, want %d", byBatch, len(stream.miner.Transactions), len(current))
+		}
+		checkAgainstReference(t, fmt.Sprintf("by batch %v", byBatch), delta.Result.HighUtilityItemsets.Items(), reference(t, current, 10))
+	}
+}
+
+func TestParallelSearchMatchesSequential(t *testing.T) {
+	type mode struct {
+		name      string
+		configure func(*EMHUN)
+	}
+	modes := []mode{
+		{"normal", func(*EMHUN) {}},
+		{"closed", func(e *EMHUN) { e.Closed = true }},
+		{"maximal", func(e *EMHUN) { e.Maximal = true }},
+		{"top-k", func(e *EMHUN) { e.TopK = 5 }},
+	}
+
+	for seed := int64(0); seed < randomSeeds/3; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		transactions := randomDatabase(r, 10+r.Intn(20))
+		minUtility := float64(5 + r.Intn(35))
+
+		for _, m := range modes {
+			sequential := NewEMHUN(cloneTransactions(transactions), minUtility)
+			m.configure(sequential)
+			want := sequential.Run().HighUtilityItemsets.Items()
+
+			for _, splitDepth := range []int{1, 3} {
+				parallel := NewEMHUN(cloneTransactions(transactions), minUtility)
+				m.configure(parallel)
+				parallel.Workers = 4
+				parallel.SplitDepth = splitDepth
+				got := parallel.Run().HighUtilityItemsets.Items()
+
+				if len(got) != len(want) {
+					t.Errorf("seed %d %s depth %d: got %d itemsets, want %d", seed, m.name, splitDepth, len(got), len(want))
+					continue
+				}
+				for i := range want {
+					if itemsetKey(got[i].Itemset) != itemsetKey(want[i].Itemset) || got[i].Utility != want[i].Utility {
+						t.Errorf("seed %d %s depth %d: itemset %d is %v (%.2f), want %v (%.2f)", seed, m.name, splitDepth,
+							i, got[i].Itemset, got[i].Utility, want[i].Itemset, want[i].Utility)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+// cancellingLogger hủy context sau một số thông điệp ở mức Node, tức là sau một số nút tìm kiếm
+type cancellingLogger struct {
+	mu        sync.Mutex
+	remaining int
+	cancel    context.CancelFunc
+}
+
+func (l *cancellingLogger) Enabled(level logger.Level) bool { return level == logger.Node }
+
+func (l *cancellingLogger) Logf(level logger.Level, format string, args ...interface{}) {
+	if level != logger.Node {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.remaining--
+	if l.remaining == 0 {
+		l.cancel()
+	}
+}
+
+func TestRunContextReturnsPartialResults(t *testing.T) {
+	for seed := int64(0); seed < randomSeeds; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		transactions := randomDatabase(r, 10+r.Intn(20))
+		minUtility := float64(5 + r.Intn(35))
+		want := reference(t, transactions, minUtility)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		emhun := NewEMHUN(cloneTransactions(transactions), minUtility)
+		emhun.SetLogger(&cancellingLogger{remaining: 1 + r.Intn(40), cancel: cancel})
+		if seed%2 == 0 {
+			emhun.Workers, emhun.SplitDepth = 3, 2
+		}
+		result := emhun.RunContext(ctx)
+		cancel()
+
+		// Mọi itemset trả về phải đúng là HUI với utility chính xác
+		diff := DiffResults(result.HighUtilityItemsets.Items(), want)
+		if len(diff.Extra) > 0 || len(diff.Mismatched) > 0 {
+			t.Errorf("seed %d: partial result contains wrong itemsets:\n%s", seed, diff)
+		}
+		if !result.Incomplete && len(diff.Missing) > 0 {
+			t.Errorf("seed %d: complete result is missing itemsets:\n%s", seed, diff)
+		}
+
+		// Mọi HUI thuộc một nhánh đã hoàn tất phải có mặt. Nhánh của một itemset là item của nó
+		// đứng đầu theo thứ tự Secondary.
+		completed := convertSliceToMap(result.CompletedBranches)
+		for _, hui := range diff.Missing {
+			root, rootIndex := 0, len(emhun.SortedSecondary)
+			for _, item := range hui.Itemset {
+				if index := indexOf(emhun.SortedSecondary, item); index != -1 && index < rootIndex {
+					root, rootIndex = item, index
+				}
+			}
+			if completed[root] {
+				t.Errorf("seed %d: %v is missing although branch %d was completed", seed, hui.Itemset, root)
+			}
+		}
+	}
+}
+
+func TestRunContextCompletesAllBranches(t *testing.T) {
+	transactions := readTable3(t)
+	emhun := NewEMHUN(transactions, 10)
+	result := emhun.RunContext(context.Background())
+	if result.Incomplete {
+		t.Fatal("uncancelled run reported an incomplete result")
+	}
+	if fmt.Sprint(result.CompletedBranches) != fmt.Sprint(emhun.PrimaryItems) {
+		t.Errorf("completed branches %v, want every primary item %v", result.CompletedBranches, emhun.PrimaryItems)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	emhun = NewEMHUN(readTable3(t), 10)
+	result = emhun.RunContext(ctx)
+	if !result.Incomplete || len(result.CompletedBranches) != 0 || result.HighUtilityItemsets.Len() != 0 {
+		t.Errorf("cancelled run: incomplete %v, %d completed branches, %d HUIs",
+			result.Incomplete, len(result.CompletedBranches), result.HighUtilityItemsets.Len())
+	}
+	if _, err := emhun.AppendTransactions(readTable3(t)); err != ErrIncompleteRun {
+		t.Errorf("AppendTransactions after a cancelled run returned %v, want ErrIncompleteRun", err)
+	}
+}
+
+func TestSearchStats(t *testing.T) {
+	for seed := int64(0); seed < randomSeeds/3; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		transactions := randomDatabase(r, 10+r.Intn(20))
+		minUtility := float64(5 + r.Intn(30))
+
+		sequential := NewEMHUN(cloneTransactions(transactions), minUtility).Run()
+		emhun := NewEMHUN(cloneTransactions(transactions), minUtility)
+		emhun.Workers, emhun.SplitDepth = 3, 2
+		parallel := emhun.Run()
+
+		name := fmt.Sprintf("seed %d", seed)
+		stats := sequential.Stats.Search
+		// Ở chế độ thường ngưỡng không đổi nên cây tìm kiếm không phụ thuộc thứ tự duyệt
+		if stats != parallel.Stats.Search {
+			t.Errorf("%s: sequential stats %+v differ from parallel stats %+v", name, stats, parallel.Stats.Search)
+		}
+		if stats.Nodes < int64(sequential.Stats.Primary) {
+			t.Errorf("%s: %d nodes visited but %d Primary items", name, stats.Nodes, sequential.Stats.Primary)
+		}
+		if stats.PrunedRTWU+int64(sequential.Stats.Secondary) != int64(sequential.Stats.Rho+sequential.Stats.Delta) {
+			t.Errorf("%s: RTWU pruned %d of %d items but Secondary has %d", name,
+				stats.PrunedRTWU, sequential.Stats.Rho+sequential.Stats.Delta, sequential.Stats.Secondary)
+		}
+		for _, hui := range sequential.HighUtilityItemsets.Items() {
+			if int64(len(hui.Itemset)) > stats.MaxDepth {
+				t.Errorf("%s: HUI %v is longer than the maximum depth %d", name, hui.Itemset, stats.MaxDepth)
+			}
+		}
+		if stats.Nodes+stats.NodesN < int64(sequential.HighUtilityItemsets.Len()) {
+			t.Errorf("%s: %d nodes visited but %d HUIs found", name, stats.Nodes+stats.NodesN, sequential.HighUtilityItemsets.Len())
+		}
+	}
+}
+
+func TestOccurrencesMatchReference(t *testing.T) {
+	for seed := int64(0); seed < randomSeeds/3; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		transactions := randomDatabase(r, 5+r.Intn(20))
+		for i, transaction := range transactions {
+			transaction.TID = 10 * (i + 1)
+		}
+		minUtility := float64(5 + r.Intn(30))
+		want := make(map[string]*models.HighUtilityItemset)
+		for _, hui := range reference(t, transactions, minUtility) {
+			want[itemsetKey(hui.Itemset)] = hui
+		}
+
+		for _, limit := range []int{-1, 2} {
+			emhun := NewEMHUN(cloneTransactions(transactions), minUtility)
+			emhun.MaxTIDs = limit
+			if seed%2 == 1 {
+				emhun.Workers = 3
+			}
+			for _, hui := range emhun.Run().HighUtilityItemsets.Items() {
+				name := fmt.Sprintf("seed %d limit %d itemset %v", seed, limit, hui.Itemset)
+				ref := want[itemsetKey(hui.Itemset)]
+				if ref == nil {
+					t.Errorf("%s: not in the reference result", name)
+					continue
+				}
+				if hui.Support != ref.Support || hui.MinUtility != ref.MinUtility || hui.MaxUtility != ref.MaxUtility {
+					t.Errorf("%s: support %d, min %.2f, max %.2f; want %d, %.2f, %.2f", name,
+						hui.Support, hui.MinUtility, hui.MaxUtility, ref.Support, ref.MinUtility, ref.MaxUtility)
+				}
+				wantTIDs := ref.TIDs
+				if limit > 0 && len(wantTIDs) > limit {
+					wantTIDs = wantTIDs[:limit]
+				}
+				if fmt.Sprint(hui.TIDs) != fmt.Sprint(wantTIDs) || hui.TIDsTruncated != (len(wantTIDs) < len(ref.TIDs)) {
+					t.Errorf("%s: TIDs %v (truncated %v), want %v of %v", name, hui.TIDs, hui.TIDsTruncated, wantTIDs, ref.TIDs)
+				}
+			}
+		}
+	}
+}
+
+func TestProjectMatchesCopyProjection(t *testing.T) {
+	for seed := int64(0); seed < randomSeeds; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		emhun := NewEMHUN(randomDatabase(r, 5+r.Intn(15)), 1)
+		emhun.Run()
+		s := emhun.SearchAlgorithms
+		items := len(emhun.remap.original)
+
+		// Chiếu theo mọi cặp item x < y: vị trí và utility phải khớp với cách chiếu sao chép giao dịch
+		for x := 0; x < items; x++ {
+			projected, _, _ := s.project(models.NewProjection(emhun.searchMap[x]), x)
+			copiedX, _ := copyProjection(emhun.searchMap, []int{x})
+			for y := x + 1; y < items; y++ {
+				got, utility, occ := s.project(projected, y)
+				copied, want := copyProjection(copiedX, []int{x, y})
+				if utility != want || occ.support != len(copied[x]) {
+					t.Fatalf("seed %d: {%d, %d} has utility %.2f in %d transactions, want %.2f in %d",
+						seed, x, y, utility, occ.support, want, len(copied[x]))
+				}
+				for _, entry := range got {
+					if entry.Transaction.Items[entry.Offset-1] != y {
+						t.Fatalf("seed %d: offset %d of {%d, %d} in %v is not after %d", seed, entry.Offset, x, y, entry.Transaction.Items, y)
+					}
+				}
+			}
+		}
+	}
+}
+
+// readPublishedResults đọc các dòng "Itemset: [...], Utility: ..." của một file kết quả trong output/
+func readPublishedResults(t *testing.T, fileName string) []*models.HighUtilityItemset {
+	t.Helper()
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Skipf("cannot read %s: %v", fileName, err)
+	}
+	var huis []*models.HighUtilityItemset
+	for _, line := range strings.Split(string(content), "\n") {
+		if !strings.HasPrefix(line, "Itemset: [") {
+			continue
+		}
+		itemsField, utilityField, _ := strings.Cut(strings.TrimPrefix(line, "Itemset: ["), "], Utility: ")
+		var itemset []int
+		for _, field := range strings.Fields(itemsField) {
+			item, err := strconv.Atoi(field)
+			if err != nil {
+				t.Fatalf("%s: bad line %q", fileName, line)
+			}
+			itemset = append(itemset, item)
+		}
+		utility, err := strconv.ParseFloat(utilityField, 64)
+		if err != nil {
+			t.Fatalf("%s: bad line %q", fileName, line)
+		}
+		huis = append(huis, &models.HighUtilityItemset{Itemset: itemset, Utility: utility})
+	}
+	return huis
+}
+
+// repeatedItems trả về các item xuất hiện hai lần trong cùng một giao dịch của file dữ liệu
+func repeatedItems(t *testing.T, fileName string) map[int]bool {
+	t.Helper()
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repeated := make(map[int]bool)
+	for _, line := range strings.Split(string(content), "\n") {
+		itemsField, _, _ := strings.Cut(line, ":")
+		seen := make(map[string]bool)
+		for _, field := range strings.Fields(itemsField) {
+			if seen[field] {
+				item, _ := strconv.Atoi(field)
+				repeated[item] = true
+			}
+			seen[field] = true
+		}
+	}
+	return repeated
+}
+
+// Các kết quả trong output/ được khai thác trước khi có bước kiểm tra dataset, khi item lặp trong một giao dịch
+// được giữ nguyên cả hai lần với utility của lần cuối. Đọc với MergeDuplicates cho đúng các itemset đó; chỉ
+// utility của itemset chứa item lặp khác đi.
+func TestFoodmartMatchesPublishedResults(t *testing.T) {
+	if testing.Short() {
+		t.Skip("mines the full foodmart dataset")
+	}
+	const fileName = "../data/foodmart_dynamic.txt"
+	transactions, report, err := dataset.Load(fileName, dataset.Options{MergeDuplicates: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Merged != 37 {
+		t.Errorf("merged %d transactions, want 37", report.Merged)
+	}
+	repeated := repeatedItems(t, fileName)
+
+	for _, minUtility := range []float64{5000, 15000, 20000, 30000} {
+		published := readPublishedResults(t, fmt.Sprintf("../output/foodmart_dynamic%.0f.txt", minUtility))
+		got := NewEMHUN(cloneTransactions(transactions), minUtility).Run().HighUtilityItemsets.Items()
+		diff := DiffResults(got, published)
+		if len(diff.Missing) > 0 || len(diff.Extra) > 0 {
+			t.Errorf("minutil %.0f: itemsets differ from the published results:\n%s", minUtility, diff)
+		}
+		for _, mismatch := range diff.Mismatched {
+			if !containsAny(mismatch.Itemset, repeated, nil) {
+				t.Errorf("minutil %.0f: %v has utility %.2f, published %.2f", minUtility, mismatch.Itemset, mismatch.Got, mismatch.Want)
+			}
+		}
+	}
+}
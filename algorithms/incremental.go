@@ -0,0 +1,514 @@
+package algorithms
+
+import (
+	"EMHUNer/logger"
+	"EMHUNer/models"
+	"EMHUNer/utility"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+var (
+	// ErrNotMined được trả về khi cập nhật tăng dần trước khi Run hoàn tất
+	ErrNotMined = errors.New("algorithms: Run must complete before incremental updates")
+	// ErrIncompleteRun được trả về khi cập nhật tăng dần sau một lần Run bị dừng giữa chừng
+	ErrIncompleteRun = errors.New("algorithms: incremental updates require a complete run")
+	// ErrSinkIncremental được trả về khi cập nhật tăng dần sau một lần Run đã gửi HUI vào Sink mà không giữ lại
+	ErrSinkIncremental = errors.New("algorithms: incremental updates require the HUIs of Run, which were delivered to a Sink")
+	// ErrTopKIncremental được trả về khi cập nhật tăng dần ở chế độ top-k
+	ErrTopKIncremental = errors.New("algorithms: incremental updates are not supported in top-k mode")
+)
+
+// Delta mô tả thay đổi của tập HUI sau một lần cập nhật tăng dần
+type Delta struct {
+	Added   []*models.HighUtilityItemset // itemset trở thành HUI
+	Removed []*models.HighUtilityItemset // itemset không còn là HUI (với utility trước khi cập nhật)
+	Updated []*models.HighUtilityItemset // vẫn là HUI nhưng utility thay đổi (với utility mới)
+	Result  *Result
+}
+
+// Update gom các thay đổi được áp dụng trong một lần cập nhật tăng dần.
+// Giao dịch được xác định bằng TID; giao dịch trong Modify phải là giá trị mới, không phải
+// giao dịch cũ bị sửa tại chỗ, để phần đóng góp cũ còn được trừ ra chính xác.
+type Update struct {
+	Append []*models.Transaction
+	Delete []int
+	Modify []*models.Transaction
+}
+
+// AppendTransactions thêm một lô giao dịch vào cơ sở dữ liệu đã được khai thác bởi Run và cập nhật
+// tập HUI mà không khai thác lại từ đầu. RTWU, phân loại ρ/δ/η và ItemTransactionMap được cập nhật
+// trực tiếp; việc tìm kiếm chỉ đi vào các nhánh có itemset nằm trong ít nhất một giao dịch mới,
+// vì utility của mọi itemset khác không đổi.
+func (e *EMHUN) AppendTransactions(batch []*models.Transaction) (*Delta, error) {
+	return e.ApplyUpdate(Update{Append: batch})
+}
+
+// DeleteTransactions xóa các giao dịch theo TID (ví dụ hàng trả lại, giỏ hàng bị hủy)
+func (e *EMHUN) DeleteTransactions(tids []int) (*Delta, error) {
+	return e.ApplyUpdate(Update{Delete: tids})
+}
+
+// ModifyTransactions thay thế các giao dịch có cùng TID bằng phiên bản mới
+func (e *EMHUN) ModifyTransactions(transactions []*models.Transaction) (*Delta, error) {
+	return e.ApplyUpdate(Update{Modify: transactions})
+}
+
+// ApplyUpdate áp dụng các thao tác thêm, xóa, sửa giao dịch rồi cập nhật tập HUI.
+// Chỉ các itemset nằm trong một giao dịch bị ảnh hưởng (phiên bản cũ hoặc mới) mới có thể thay đổi
+// utility, nên việc tìm kiếm được giới hạn trong các nhánh đó. Một item có thể đổi nhóm ρ/δ/η khi lần
+// xuất hiện âm (hoặc dương) duy nhất của nó bị xóa; phân loại được tính lại từ số lần xuất hiện.
+func (e *EMHUN) ApplyUpdate(update Update) (*Delta, error) {
+	if e.ItemTransactionMap == nil {
+		return nil, ErrNotMined
+	}
+	if e.TopK > 0 {
+		return nil, ErrTopKIncremental
+	}
+	if e.incomplete {
+		return nil, ErrIncompleteRun
+	}
+	if e.streamed {
+		return nil, ErrSinkIncremental
+	}
+	if err := e.validateUpdate(update); err != nil {
+		return nil, err
+	}
+	e.Logger.Logf(logger.Summary, "Updating: %d appended, %d deleted, %d modified transactions...\n",
+		len(update.Append), len(update.Delete), len(update.Modify))
+
+	var scope []map[int]bool
+	var removed, added []*models.Transaction // phiên bản cũ và mới của các giao dịch bị thay đổi
+	changed := make(map[int]bool)
+	deleted := make(map[int]bool)
+	modified := make(map[int]*models.Transaction)
+
+	for _, tid := range update.Delete {
+		old := e.transactionsByID[tid]
+		scope = append(scope, convertSliceToMap(old.Items))
+		e.removeTransaction(old, changed)
+		removed = append(removed, old)
+		deleted[tid] = true
+	}
+	for _, transaction := range update.Modify {
+		old := e.transactionsByID[transaction.TID]
+		scope = append(scope, convertSliceToMap(old.Items), convertSliceToMap(transaction.Items))
+		e.removeTransaction(old, changed)
+		e.addTransaction(transaction, changed)
+		removed = append(removed, old)
+		added = append(added, transaction)
+		modified[transaction.TID] = transaction
+	}
+
+	// Không ghi đè lên mảng của người gọi: danh sách giao dịch được sao chép một lần ở lần cập nhật đầu tiên,
+	// sau đó được sửa và nối thêm tại chỗ
+	if !e.ownsTransactions {
+		e.Transactions = append([]*models.Transaction(nil), e.Transactions...)
+		e.ownsTransactions = true
+	}
+	if len(deleted) > 0 || len(modified) > 0 {
+		expired := 0
+		for expired < len(e.Transactions) && deleted[e.Transactions[expired].TID] {
+			expired++
+		}
+		if expired == len(deleted) && len(modified) == 0 {
+			// Các giao dịch bị xóa là các giao dịch cũ nhất (cửa sổ trượt): chỉ cần bỏ phần đầu danh sách
+			for i := 0; i < expired; i++ {
+				e.Transactions[i] = nil
+			}
+			e.Transactions = e.Transactions[expired:]
+		} else {
+			kept := e.Transactions[:0]
+			for _, transaction := range e.Transactions {
+				if deleted[transaction.TID] {
+					continue
+				}
+				if replacement, ok := modified[transaction.TID]; ok {
+					transaction = replacement
+				}
+				kept = append(kept, transaction)
+			}
+			for i := len(kept); i < len(e.Transactions); i++ {
+				e.Transactions[i] = nil
+			}
+			e.Transactions = kept
+		}
+	}
+
+	for _, transaction := range update.Append {
+		if transaction.TID == 0 {
+			e.nextTID++
+			transaction.TID = e.nextTID
+		} else if transaction.TID > e.nextTID {
+			e.nextTID = transaction.TID
+		}
+		scope = append(scope, convertSliceToMap(transaction.Items))
+		e.addTransaction(transaction, changed)
+		added = append(added, transaction)
+		e.Transactions = append(e.Transactions, transaction)
+	}
+
+	for item := range changed {
+		e.classifyItem(item)
+	}
+
+	return e.refresh(scope, removed, added), nil
+}
+
+// validateUpdate kiểm tra toàn bộ thay đổi trước khi áp dụng để không để lại trạng thái dở dang
+func (e *EMHUN) validateUpdate(update Update) error {
+	checkShape := func(transaction *models.Transaction) error {
+		if len(transaction.Items) != len(transaction.Utilities) {
+			return fmt.Errorf("algorithms: transaction %d has %d items but %d utilities",
+				transaction.TID, len(transaction.Items), len(transaction.Utilities))
+		}
+		return nil
+	}
+
+	touched := make(map[int]bool)
+	for _, tid := range update.Delete {
+		if _, ok := e.transactionsByID[tid]; !ok {
+			return fmt.Errorf("algorithms: cannot delete unknown transaction %d", tid)
+		}
+		if touched[tid] {
+			return fmt.Errorf("algorithms: transaction %d is changed more than once", tid)
+		}
+		touched[tid] = true
+	}
+	for _, transaction := range update.Modify {
+		old, ok := e.transactionsByID[transaction.TID]
+		if !ok {
+			return fmt.Errorf("algorithms: cannot modify unknown transaction %d", transaction.TID)
+		}
+		if old == transaction {
+			return fmt.Errorf("algorithms: modified transaction %d must be a new value, not the stored one", transaction.TID)
+		}
+		if touched[transaction.TID] {
+			return fmt.Errorf("algorithms: transaction %d is changed more than once", transaction.TID)
+		}
+		touched[transaction.TID] = true
+		if err := checkShape(transaction); err != nil {
+			return err
+		}
+	}
+	for _, transaction := range update.Append {
+		if transaction.TID != 0 {
+			if _, exists := e.transactionsByID[transaction.TID]; exists || touched[transaction.TID] {
+				return fmt.Errorf("algorithms: appended transaction %d already exists", transaction.TID)
+			}
+			touched[transaction.TID] = true
+		}
+		if err := checkShape(transaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTransaction cộng phần đóng góp của một giao dịch vào RTWU, số lần xuất hiện và ItemTransactionMap
+func (e *EMHUN) addTransaction(transaction *models.Transaction, changed map[int]bool) {
+	rtu := utility.CalculateRTUForTransaction(transaction)
+	e.TotalUtility += rtu
+
+	for i, item := range transaction.Items {
+		_, known := e.RTWU[item]
+		if transaction.Utilities[i] > 0 {
+			e.positiveCount[item]++
+		} else if transaction.Utilities[i] < 0 {
+			e.negativeCount[item]++
+		} else {
+			e.zeroCount[item]++
+		}
+		e.RTWU[item] += rtu
+		changed[item] = true
+
+		// Danh sách giao dịch của item đã bị loại ở lần chạy trước không còn đầy đủ,
+		// nên chỉ nối thêm khi danh sách còn được giữ hoặc item hoàn toàn mới;
+		// các item còn lại được dựng lại trong refresh nếu cần
+		if _, indexed := e.ItemTransactionMap[item]; indexed || !known {
+			e.addItemToTransactionMap(item, transaction)
+		}
+	}
+	e.transactionsByID[transaction.TID] = transaction
+}
+
+// removeTransaction trừ phần đóng góp của một giao dịch khỏi RTWU, số lần xuất hiện và ItemTransactionMap
+func (e *EMHUN) removeTransaction(transaction *models.Transaction, changed map[int]bool) {
+	rtu := utility.CalculateRTUForTransaction(transaction)
+	e.TotalUtility -= rtu
+
+	for i, item := range transaction.Items {
+		if transaction.Utilities[i] > 0 {
+			e.positiveCount[item]--
+		} else if transaction.Utilities[i] < 0 {
+			e.negativeCount[item]--
+		} else {
+			e.zeroCount[item]--
+		}
+		e.RTWU[item] -= rtu
+		e.removeItemFromTransactionMap(item, transaction)
+		changed[item] = true
+
+		// Item không còn xuất hiện ở đâu: quên nó hoàn toàn để lần thêm sau coi như item mới
+		if e.positiveCount[item] == 0 && e.negativeCount[item] == 0 && e.zeroCount[item] == 0 {
+			delete(e.positiveCount, item)
+			delete(e.negativeCount, item)
+			delete(e.zeroCount, item)
+			delete(e.RTWU, item)
+			e.deleteItemTransactions(item)
+		}
+	}
+	delete(e.transactionsByID, transaction.TID)
+}
+
+// refresh tính lại Secondary/Primary sau khi cơ sở dữ liệu thay đổi, tìm lại các HUI trong phạm vi
+// scope (các itemset chứa trong một giao dịch bị thay đổi) và ghép với các HUI cũ không bị ảnh hưởng.
+// removed và added là phiên bản cũ và mới của các giao dịch bị thay đổi.
+//
+// Khi tập item Secondary ∪ η và nhóm ρ/δ/η của chúng không đổi, thứ tự xử lý của lần trước vẫn được
+// dùng và cơ sở dữ liệu tìm kiếm chỉ được sửa theo các giao dịch bị thay đổi (xem patchSearch).
+// Thứ tự RTWU trong một nhóm chỉ ảnh hưởng đến hiệu quả cắt tỉa, không ảnh hưởng đến tập HUI.
+// Ngược lại, các item được đánh lại số và cơ sở dữ liệu tìm kiếm được dựng lại toàn bộ.
+func (e *EMHUN) refresh(scope []map[int]bool, removed, added []*models.Transaction) *Delta {
+	previous := e.SearchAlgorithms.HighUtilityItemsets
+	previousMinUtility := e.MinUtility
+	if e.MinUtilityRatio > 0 {
+		e.MinUtility = e.MinUtilityRatio * e.TotalUtility
+	}
+	// Khi ngưỡng giảm, các itemset nằm ngoài phạm vi cũng có thể trở thành HUI nên phải tìm lại toàn bộ
+	if e.MinUtility < previousMinUtility {
+		e.Logger.Logf(logger.Summary, "minUtility decreased from %.2f to %.2f, searching the whole database\n",
+			previousMinUtility, e.MinUtility)
+		scope = nil
+	}
+
+	// Tính cực đại phụ thuộc vào các itemset khác, kể cả ngoài phạm vi, nên chế độ maximal luôn tìm lại toàn bộ
+	if e.SearchAlgorithms.Maximal {
+		scope = nil
+	}
+
+	// ItemNames có thể đã được bổ sung tên cho các item mới của lần cập nhật
+	e.SearchAlgorithms.ItemNames = e.ItemNames
+
+	// Thống kê của một lần cập nhật chỉ gồm phần việc của lần cập nhật đó
+	e.SearchAlgorithms.stats = SearchStats{}
+	e.phases = PhaseTimings{}
+	combinedSet := e.unionKeys(e.Rho, e.Delta)
+	e.timePhase("filtering", &e.phases.Filtering, func() { e.rebuildItemTransactions(combinedSet) })
+	var secondaryItems []int
+	e.timePhase("filtering", &e.phases.Filtering, func() {
+		secondaryItems = e.getSecondaryItems(combinedSet, e.RTWU, e.MinUtility)
+	})
+	if e.sameSearchItems(secondaryItems) {
+		e.patchSearch(len(combinedSet)-len(secondaryItems), removed, added)
+	} else {
+		e.prepareSearch(combinedSet)
+	}
+
+	e.Logger.Logf(logger.Summary, "Starting incremental HUI Search...\n")
+	e.SearchAlgorithms.HighUtilityItemsets = []*models.HighUtilityItemset{}
+	e.SearchAlgorithms.scope = e.remap.scope(scope)
+	e.timePhase("search", &e.phases.Search, e.search)
+	e.SearchAlgorithms.scope = nil
+	found := e.SearchAlgorithms.HighUtilityItemsets
+
+	delta := &Delta{}
+	foundByKey := make(map[string]*models.HighUtilityItemset, len(found))
+	for _, hui := range found {
+		foundByKey[itemsetKey(hui.Itemset)] = hui
+	}
+
+	merged := make([]*models.HighUtilityItemset, 0, len(previous)+len(found))
+	previousKeys := make(map[string]bool, len(previous))
+	for _, hui := range previous {
+		key := itemsetKey(hui.Itemset)
+		previousKeys[key] = true
+
+		// Itemset ngoài phạm vi giữ nguyên utility, chỉ cần so lại với ngưỡng mới
+		if scope != nil && !containedInAny(hui.Itemset, scope) {
+			if hui.Utility >= e.MinUtility {
+				merged = append(merged, hui)
+			} else {
+				delta.Removed = append(delta.Removed, hui)
+			}
+			continue
+		}
+
+		current, ok := foundByKey[key]
+		if !ok {
+			delta.Removed = append(delta.Removed, hui)
+		} else if current.Utility != hui.Utility {
+			delta.Updated = append(delta.Updated, current)
+		}
+	}
+	for _, hui := range found {
+		merged = append(merged, hui)
+		if !previousKeys[itemsetKey(hui.Itemset)] {
+			delta.Added = append(delta.Added, hui)
+		}
+	}
+
+	e.SearchAlgorithms.HighUtilityItemsets = merged
+	e.Logger.Logf(logger.Summary, "Incremental update: %d added, %d removed, %d updated\n",
+		len(delta.Added), len(delta.Removed), len(delta.Updated))
+	delta.Result = e.result()
+	// Thay các HUI mới tìm thấy bằng bản đã chuẩn hóa trong kết quả
+	for i, hui := range delta.Added {
+		delta.Added[i] = delta.Result.HighUtilityItemsets.Get(hui.Itemset)
+	}
+	for i, hui := range delta.Updated {
+		delta.Updated[i] = delta.Result.HighUtilityItemsets.Get(hui.Itemset)
+	}
+	return delta
+}
+
+// sameSearchItems cho biết thứ tự xử lý của lần tìm kiếm trước còn dùng được: Secondary (secondaryItems)
+// và η vẫn gồm đúng các item đã được đánh số, và các item Secondary vẫn theo thứ tự nhóm ρ rồi δ
+func (e *EMHUN) sameSearchItems(secondaryItems []int) bool {
+	if e.remap == nil || len(secondaryItems) != len(e.SortedSecondary) || len(e.Eta) != len(e.SortedEta) {
+		return false
+	}
+	for _, item := range secondaryItems {
+		if index, ok := e.remap.dense[item]; !ok || index >= len(e.SortedSecondary) {
+			return false
+		}
+	}
+	for _, item := range e.SortedEta {
+		if !e.Eta[item] {
+			return false
+		}
+	}
+	for i := 1; i < len(e.SortedSecondary); i++ {
+		if e.getTypeOrder(e.SortedSecondary[i-1]) > e.getTypeOrder(e.SortedSecondary[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// patchSearch cập nhật cơ sở dữ liệu tìm kiếm và RSU theo các giao dịch bị thay đổi mà không đánh lại số
+// item: bản sao của các giao dịch trong removed được gỡ khỏi searchMap, bản sao của các giao dịch trong
+// added được chèn vào, phần đóng góp RSU của chúng được trừ và cộng tương ứng, rồi Primary được xác định lại.
+// prunedRTWU là số item ρ ∪ δ bị loại bởi RTWU.
+func (e *EMHUN) patchSearch(prunedRTWU int, removed, added []*models.Transaction) {
+	e.SearchAlgorithms.stats.PrunedRTWU = int64(prunedRTWU)
+	secondary := len(e.SortedSecondary)
+
+	e.timePhase("sorting", &e.phases.Sorting, func() {
+		e.Logger.Logf(logger.Summary, "Patching search database: %d removed, %d added transactions\n", len(removed), len(added))
+		for _, transaction := range removed {
+			dense, ok := e.searchByTID[transaction.TID]
+			if !ok {
+				continue
+			}
+			delete(e.searchByTID, transaction.TID)
+			for _, item := range dense.Items {
+				e.searchMap[item] = removeByUtility(e.searchMap[item], dense)
+			}
+			addRSU(e.UtilityArray, dense, secondary, -1)
+		}
+		for _, transaction := range added {
+			// Item không được đánh số không cần danh sách giao dịch; danh sách sẽ được dựng lại nếu cần
+			for _, item := range transaction.Items {
+				if _, ok := e.remap.dense[item]; !ok {
+					e.deleteItemTransactions(item)
+				}
+			}
+			dense := e.remap.transaction(transaction)
+			if len(dense.Items) == 0 {
+				continue
+			}
+			e.searchByTID[transaction.TID] = dense
+			for _, item := range dense.Items {
+				e.searchMap[item] = insertByUtility(e.searchMap[item], dense)
+			}
+			addRSU(e.UtilityArray, dense, secondary, 1)
+		}
+		for index, item := range e.remap.original {
+			e.UtilityArray.SetRTWU(index, e.RTWU[item])
+		}
+	})
+
+	e.timePhase("rsu", &e.phases.RSU, func() {
+		e.PrimaryItems = nil
+		e.identifyPrimaryItems()
+	})
+	e.SearchAlgorithms.stats.PrunedRSU += int64(len(e.SortedSecondary) - len(e.PrimaryItems))
+	e.Logger.Logf(logger.Summary, "Primary: %d items\n", len(e.PrimaryItems))
+	e.Logger.Logf(logger.Node, "Primary: %v\n", e.PrimaryItems)
+}
+
+// addRSU cộng (sign = 1) hoặc trừ (sign = -1) phần đóng góp của một giao dịch đã đánh lại số vào RSU
+// của các item Secondary (chỉ số nhỏ hơn secondary), như utility.CalculateRSUForAllItems
+func addRSU(utilityArray *models.UtilityArray, transaction *models.Transaction, secondary int, sign float64) {
+	remaining := 0.0
+	for i := len(transaction.Items) - 1; i >= 0; i-- {
+		item := transaction.Items[i]
+		if item < secondary {
+			contribution := math.Max(0, transaction.Utilities[i]+remaining)
+			utilityArray.SetRSU(item, utilityArray.GetRSU(item)+sign*contribution)
+		}
+		if transaction.Utilities[i] > 0 {
+			remaining += transaction.Utilities[i]
+		}
+	}
+}
+
+// rebuildItemTransactions dựng lại danh sách giao dịch của các item lại cần cho việc tìm kiếm
+// (thuộc η hoặc có RTWU đạt ngưỡng) nhưng đã bị loại khỏi ItemTransactionMap ở lần trước,
+// bằng một lượt duyệt toàn bộ giao dịch
+func (e *EMHUN) rebuildItemTransactions(combinedSet map[int]bool) {
+	needed := make(map[int]bool)
+	for item := range e.unionKeys(combinedSet, e.Eta) {
+		if _, indexed := e.ItemTransactionMap[item]; indexed {
+			continue
+		}
+		if e.Eta[item] || e.RTWU[item] >= e.MinUtility {
+			needed[item] = true
+		}
+	}
+	if len(needed) == 0 {
+		return
+	}
+
+	e.Logger.Logf(logger.Summary, "Rebuilding transaction lists of %d items\n", len(needed))
+	for _, transaction := range e.Transactions {
+		for _, item := range transaction.Items {
+			if needed[item] {
+				e.addItemToTransactionMap(item, transaction)
+			}
+		}
+	}
+}
+
+// containedInAny cho biết itemset có nằm trọn trong ít nhất một tập của scope hay không
+func containedInAny(itemset []int, scope []map[int]bool) bool {
+	for _, items := range scope {
+		contained := true
+		for _, item := range itemset {
+			if !items[item] {
+				contained = false
+				break
+			}
+		}
+		if contained {
+			return true
+		}
+	}
+	return false
+}
+
+// inScope cho biết itemset có nằm trong phạm vi tìm kiếm hiện tại hay không.
+// Khi scope rỗng (nil), mọi itemset đều thuộc phạm vi.
+func (s *SearchAlgorithms) inScope(itemset []int) bool {
+	return s.scope == nil || containedInAny(itemset, s.scope)
+}
+
+// itemsetKey trả về khóa không phụ thuộc thứ tự item để so sánh itemset giữa các lần chạy
+func itemsetKey(itemset []int) string {
+	sorted := append([]int(nil), itemset...)
+	sort.Ints(sorted)
+	return fmt.Sprint(sorted)
+}
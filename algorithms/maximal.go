@@ -0,0 +1,106 @@
+package algorithms
+
+import (
+	"EMHUNer/logger"
+	"EMHUNer/models"
+	"fmt"
+)
+
+// recordMaximal thêm một HUI vào tập HUI cực đại hiện tại: bỏ qua nếu đã có một HUI chứa nó,
+// ngược lại loại các HUI là tập con thực sự của nó rồi thêm nó vào. Người gọi giữ s.mu.
+func (s *SearchAlgorithms) recordMaximal(hui *models.HighUtilityItemset) {
+	candidate := convertSliceToMap(hui.Itemset)
+	kept := s.HighUtilityItemsets[:0]
+	for _, existing := range s.HighUtilityItemsets {
+		if isSubset(hui.Itemset, existing.Itemset) {
+			s.Logger.Logf(logger.Node, "%v is subsumed by %v, skipped.\n", hui.Itemset, existing.Itemset)
+			return
+		}
+		if !isSubsetOfMap(existing.Itemset, candidate) {
+			kept = append(kept, existing)
+		}
+	}
+	s.HighUtilityItemsets = append(kept, hui)
+}
+
+// branchSubsumed cho biết mọi itemset trong nhánh của itemset đều là tập con của một HUI đã tìm thấy.
+// Nhánh chỉ có thể mở rộng itemset bằng các item trong candidates cùng xuất hiện với nó trong
+// một giao dịch (itemset có support 0 không thể là HUI), nên chỉ cần so hợp của chúng với các HUI cực đại.
+func (s *SearchAlgorithms) branchSubsumed(itemset []int, projection models.Projection, candidates ...[]int) bool {
+	candidateSet := make(map[int]bool)
+	for _, items := range candidates {
+		for _, item := range items {
+			candidateSet[item] = true
+		}
+	}
+
+	// Các item của candidates đều đứng sau itemset nên chỉ cần xét phần giao dịch từ Offset trở đi
+	bound := convertSliceToMap(itemset)
+	for _, entry := range projection {
+		for _, item := range entry.Transaction.Items[entry.Offset:] {
+			if candidateSet[item] {
+				bound[item] = true
+			}
+		}
+	}
+
+	// Các HUI đã tìm thấy dùng mã item gốc
+	items := s.remap.originalItems(mapKeys(bound))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.HighUtilityItemsets {
+		if len(existing.Itemset) >= len(items) && isSubsetOfMap(items, convertSliceToMap(existing.Itemset)) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyMaximal kiểm tra maximal có đúng là tập HUI cực đại của all (kết quả của một lần chạy thường):
+// mỗi itemset của maximal phải là HUI không có tập cha thực sự nào trong all,
+// và mọi HUI của all phải nằm trong ít nhất một itemset của maximal.
+func VerifyMaximal(maximal, all []*models.HighUtilityItemset) error {
+	allByKey := make(map[string]*models.HighUtilityItemset, len(all))
+	for _, hui := range all {
+		allByKey[itemsetKey(hui.Itemset)] = hui
+	}
+
+	for _, hui := range maximal {
+		if _, ok := allByKey[itemsetKey(hui.Itemset)]; !ok {
+			return fmt.Errorf("algorithms: %v is not a high-utility itemset", hui.Itemset)
+		}
+		for _, other := range all {
+			if len(other.Itemset) > len(hui.Itemset) && isSubset(hui.Itemset, other.Itemset) {
+				return fmt.Errorf("algorithms: %v is not maximal, %v is a high-utility superset", hui.Itemset, other.Itemset)
+			}
+		}
+	}
+
+	for _, hui := range all {
+		covered := false
+		for _, m := range maximal {
+			if isSubset(hui.Itemset, m.Itemset) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return fmt.Errorf("algorithms: %v is not contained in any maximal itemset", hui.Itemset)
+		}
+	}
+	return nil
+}
+
+// isSubset cho biết mọi item của a đều thuộc b
+func isSubset(a, b []int) bool {
+	return isSubsetOfMap(a, convertSliceToMap(b))
+}
+
+func isSubsetOfMap(items []int, set map[int]bool) bool {
+	for _, item := range items {
+		if !set[item] {
+			return false
+		}
+	}
+	return true
+}
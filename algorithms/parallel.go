@@ -0,0 +1,155 @@
+package algorithms
+
+import (
+	"EMHUNer/logger"
+	"EMHUNer/models"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// searchTask là một nhánh β = x ∪ {item} của cây tìm kiếm cùng cơ sở dữ liệu chiếu của x
+type searchTask struct {
+	x          map[int]bool
+	item       int
+	projection models.Projection
+	secondary  []int
+	depth      int
+	root       *branchProgress // nhánh gốc ở tầng đầu chứa tác vụ này
+}
+
+// branchProgress đếm số tác vụ chưa xong của một nhánh gốc; nhánh hoàn tất khi bộ đếm về 0
+// mà chưa có tác vụ nào bị dừng vì hủy
+type branchProgress struct {
+	item    int
+	pending int64
+}
+
+func newBranchProgress(item int) *branchProgress {
+	return &branchProgress{item: item, pending: 1}
+}
+
+// finishTask đánh dấu một tác vụ đã xong. Tác vụ kết thúc khi context chưa bị hủy đã duyệt hết
+// phần việc của nó; nếu đã bị hủy, bộ đếm không giảm và nhánh gốc không bao giờ được coi là hoàn tất.
+func (s *SearchAlgorithms) finishTask(task searchTask) {
+	if s.cancelled() {
+		return
+	}
+	if atomic.AddInt64(&task.root.pending, -1) == 0 {
+		s.mu.Lock()
+		s.completed = append(s.completed, task.root.item)
+		s.mu.Unlock()
+	}
+}
+
+// cancelled cho biết context của lần chạy hiện tại đã bị hủy hay hết hạn
+func (s *SearchAlgorithms) cancelled() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// taskDeque là hàng đợi hai đầu của một worker: chủ sở hữu lấy ở cuối, worker khác lấy trộm ở đầu
+type taskDeque struct {
+	mu    sync.Mutex
+	tasks []searchTask
+}
+
+func (d *taskDeque) push(task searchTask) {
+	d.mu.Lock()
+	d.tasks = append(d.tasks, task)
+	d.mu.Unlock()
+}
+
+func (d *taskDeque) pop() (searchTask, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.tasks) == 0 {
+		return searchTask{}, false
+	}
+	task := d.tasks[len(d.tasks)-1]
+	d.tasks = d.tasks[:len(d.tasks)-1]
+	return task, true
+}
+
+func (d *taskDeque) steal() (searchTask, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.tasks) == 0 {
+		return searchTask{}, false
+	}
+	task := d.tasks[0]
+	d.tasks = d.tasks[1:]
+	return task, true
+}
+
+// SearchParallel cho kết quả giống Search nhưng các nhánh của item Primary được khai thác đồng thời
+// bởi s.Workers goroutine. Mỗi worker có hàng đợi riêng và lấy trộm việc của worker khác khi rảnh;
+// các nhánh ở s.SplitDepth tầng đầu được chia thành tác vụ, các tầng sâu hơn được duyệt tuần tự.
+func (s *SearchAlgorithms) SearchParallel(eta []int, X map[int]bool, itemTransactionMap map[int][]*models.Transaction, primary []int, secondary []int, minU float64) {
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers == 1 || len(primary) == 0 {
+		s.Search(eta, X, itemTransactionMap, primary, secondary, minU)
+		return
+	}
+
+	s.Logger.Logf(logger.Summary, "Searching with %d workers\n", workers)
+
+	deques := make([]*taskDeque, workers)
+	for i := range deques {
+		deques[i] = &taskDeque{}
+	}
+	// Chia vòng tròn các nhánh đầu tiên; đẩy theo thứ tự ngược để mỗi worker bắt đầu từ item đứng trước
+	for i := len(primary) - 1; i >= 0; i-- {
+		deques[i%workers].push(rootTask(X, primary[i], itemTransactionMap, secondary))
+	}
+	pending := int64(len(primary))
+
+	var wg sync.WaitGroup
+	var panicOnce sync.Once
+	var panicValue interface{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					panicOnce.Do(func() { panicValue = r })
+					// Đánh dấu đã xong để các worker khác không chờ mãi
+					atomic.StoreInt64(&pending, 0)
+				}
+			}()
+
+			spawn := func(task searchTask) {
+				atomic.AddInt64(&pending, 1)
+				atomic.AddInt64(&task.root.pending, 1)
+				deques[w].push(task)
+			}
+			for atomic.LoadInt64(&pending) > 0 {
+				task, ok := deques[w].pop()
+				for i := 1; !ok && i < workers; i++ {
+					task, ok = deques[(w+i)%workers].steal()
+				}
+				if !ok {
+					runtime.Gosched()
+					continue
+				}
+				s.searchItem(task, eta, s.raiseThreshold(minU), spawn)
+				s.finishTask(task)
+				atomic.AddInt64(&pending, -1)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if panicValue != nil {
+		panic(fmt.Sprintf("parallel search: %v", panicValue))
+	}
+}
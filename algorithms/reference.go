@@ -0,0 +1,140 @@
+package algorithms
+
+import (
+	"EMHUNer/models"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// MaxReferenceTransactionLength giới hạn độ dài giao dịch mà ReferenceHUIs chấp nhận (2^n tập con mỗi giao dịch)
+const MaxReferenceTransactionLength = 20
+
+// referenceTolerance là sai số cho phép khi so sánh utility giữa hai kết quả
+const referenceTolerance = 1e-6
+
+// ReferenceHUIs liệt kê vét cạn mọi itemset xuất hiện trong ít nhất một giao dịch, tính utility chính xác
+// và trả về các itemset có utility >= minUtility, item trong mỗi itemset được sắp tăng dần.
+// Itemset không xuất hiện trong giao dịch nào có utility 0 nên không cần xét khi minUtility > 0.
+// Support, MinUtility, MaxUtility và TIDs (lấy từ Transaction.TID) của mỗi itemset cũng được tính.
+// Chỉ dùng cho dữ liệu nhỏ (ví dụ data/table3.txt) để kiểm tra kết quả của EMHUN.
+func ReferenceHUIs(transactions []*models.Transaction, minUtility float64) ([]*models.HighUtilityItemset, error) {
+	type entry struct {
+		itemset     []int
+		utility     float64
+		occurrences occurrences
+		tids        []int
+	}
+	entries := make(map[string]*entry)
+
+	for index, transaction := range transactions {
+		if len(transaction.Items) != len(transaction.Utilities) {
+			return nil, fmt.Errorf("algorithms: transaction %d has %d items but %d utilities",
+				index+1, len(transaction.Items), len(transaction.Utilities))
+		}
+		if len(transaction.Items) > MaxReferenceTransactionLength {
+			return nil, fmt.Errorf("algorithms: transaction %d has %d items, reference miner supports at most %d",
+				index+1, len(transaction.Items), MaxReferenceTransactionLength)
+		}
+
+		n := len(transaction.Items)
+		for mask := 1; mask < 1<<n; mask++ {
+			var itemset []int
+			utility := 0.0
+			for i := 0; i < n; i++ {
+				if mask&(1<<i) != 0 {
+					itemset = append(itemset, transaction.Items[i])
+					utility += transaction.Utilities[i]
+				}
+			}
+			sort.Ints(itemset)
+			key := fmt.Sprint(itemset)
+			e, ok := entries[key]
+			if !ok {
+				e = &entry{itemset: itemset}
+				entries[key] = e
+			}
+			e.utility += utility
+			e.occurrences.add(utility)
+			e.tids = append(e.tids, transaction.TID)
+		}
+	}
+
+	var huis []*models.HighUtilityItemset
+	for _, e := range entries {
+		if e.utility >= minUtility {
+			hui := models.NewHighUtilityItemset(e.itemset, e.utility)
+			hui.Support = e.occurrences.support
+			hui.MinUtility = e.occurrences.minUtility
+			hui.MaxUtility = e.occurrences.maxUtility
+			hui.TIDs = e.tids
+			sort.Ints(hui.TIDs)
+			huis = append(huis, hui)
+		}
+	}
+	sort.Slice(huis, func(i, j int) bool {
+		return fmt.Sprint(huis[i].Itemset) < fmt.Sprint(huis[j].Itemset)
+	})
+	return huis, nil
+}
+
+// UtilityMismatch là một itemset có mặt ở cả hai kết quả nhưng với utility khác nhau
+type UtilityMismatch struct {
+	Itemset []int
+	Got     float64
+	Want    float64
+}
+
+// ResultDiff liệt kê khác biệt giữa kết quả cần kiểm tra và kết quả tham chiếu
+type ResultDiff struct {
+	Missing    []*models.HighUtilityItemset // có trong kết quả tham chiếu nhưng bị bỏ sót
+	Extra      []*models.HighUtilityItemset // không có trong kết quả tham chiếu
+	Mismatched []UtilityMismatch
+}
+
+// Empty cho biết hai kết quả có trùng khớp hay không
+func (d ResultDiff) Empty() bool {
+	return len(d.Missing) == 0 && len(d.Extra) == 0 && len(d.Mismatched) == 0
+}
+
+func (d ResultDiff) String() string {
+	var b strings.Builder
+	for _, hui := range d.Missing {
+		fmt.Fprintf(&b, "missing %v (utility %.2f)\n", hui.Itemset, hui.Utility)
+	}
+	for _, hui := range d.Extra {
+		fmt.Fprintf(&b, "extra %v (utility %.2f)\n", hui.Itemset, hui.Utility)
+	}
+	for _, m := range d.Mismatched {
+		fmt.Fprintf(&b, "mis-valued %v: got %.2f, want %.2f\n", m.Itemset, m.Got, m.Want)
+	}
+	return b.String()
+}
+
+// DiffResults so sánh got với want theo itemset (không phụ thuộc thứ tự item)
+func DiffResults(got, want []*models.HighUtilityItemset) ResultDiff {
+	var diff ResultDiff
+	wantByKey := make(map[string]*models.HighUtilityItemset, len(want))
+	for _, hui := range want {
+		wantByKey[itemsetKey(hui.Itemset)] = hui
+	}
+
+	seen := make(map[string]bool, len(got))
+	for _, hui := range got {
+		key := itemsetKey(hui.Itemset)
+		seen[key] = true
+		expected, ok := wantByKey[key]
+		if !ok {
+			diff.Extra = append(diff.Extra, hui)
+		} else if math.Abs(expected.Utility-hui.Utility) > referenceTolerance {
+			diff.Mismatched = append(diff.Mismatched, UtilityMismatch{Itemset: hui.Itemset, Got: hui.Utility, Want: expected.Utility})
+		}
+	}
+	for _, hui := range want {
+		if !seen[itemsetKey(hui.Itemset)] {
+			diff.Missing = append(diff.Missing, hui)
+		}
+	}
+	return diff
+}
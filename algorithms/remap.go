@@ -0,0 +1,156 @@
+package algorithms
+
+import (
+	"EMHUNer/models"
+	"sort"
+)
+
+// itemRemap đánh lại số các item còn lại sau khi lọc (Secondary ∪ η) thành 0..n-1 theo thứ tự xử lý
+// của EMHUN, để các cận của tìm kiếm được lưu trong mảng và thứ tự xử lý chỉ còn là thứ tự tăng dần
+// của chỉ số. Itemset được đổi lại sang mã item gốc khi ghi nhận HUI.
+type itemRemap struct {
+	original []int       // chỉ số → mã item gốc
+	dense    map[int]int // mã item gốc → chỉ số
+}
+
+// newItemRemap đánh số các item theo thứ tự của các danh sách đã sắp xếp được truyền vào
+func newItemRemap(ordered ...[]int) *itemRemap {
+	r := &itemRemap{dense: make(map[int]int)}
+	for _, items := range ordered {
+		for _, item := range items {
+			r.dense[item] = len(r.original)
+			r.original = append(r.original, item)
+		}
+	}
+	return r
+}
+
+// span trả về các chỉ số liên tiếp from..to-1
+func span(from, to int) []int {
+	items := make([]int, 0, to-from)
+	for item := from; item < to; item++ {
+		items = append(items, item)
+	}
+	return items
+}
+
+// originalItems trả về itemset theo mã item gốc (giữ nguyên khi chưa đánh lại số)
+func (r *itemRemap) originalItems(items []int) []int {
+	if r == nil {
+		return items
+	}
+	original := make([]int, len(items))
+	for i, item := range items {
+		original[i] = r.original[item]
+	}
+	return original
+}
+
+// denseItems trả về chỉ số của các item đã được đánh số, bỏ qua các item khác
+func (r *itemRemap) denseItems(items []int) []int {
+	dense := make([]int, 0, len(items))
+	for _, item := range items {
+		if index, ok := r.dense[item]; ok {
+			dense = append(dense, index)
+		}
+	}
+	return dense
+}
+
+// transaction trả về bản sao của giao dịch chỉ gồm các item đã được đánh số, sắp theo chỉ số tăng dần.
+// Các item bị loại có RTWU dưới ngưỡng nên không thuộc HUI nào và không cần cho các cận.
+func (r *itemRemap) transaction(transaction *models.Transaction) *models.Transaction {
+	items := make([]int, 0, len(transaction.Items))
+	utilities := make([]float64, 0, len(transaction.Items))
+	for i, item := range transaction.Items {
+		if index, ok := r.dense[item]; ok {
+			items = append(items, index)
+			utilities = append(utilities, transaction.Utilities[i])
+		}
+	}
+	dense := models.NewTransaction(items, utilities, calculateTransactionUtility(utilities))
+	dense.TID = transaction.TID
+	sort.Sort(byItem{dense})
+	return dense
+}
+
+// itemTransactionMap dựng ItemTransactionMap theo chỉ số từ danh sách giao dịch của các item đã đánh số.
+// Mỗi giao dịch gốc chỉ được sao chép một lần dù xuất hiện trong nhiều danh sách; các danh sách được
+// sắp tăng dần theo utility của giao dịch. Các bản sao cũng được trả về theo TID để cập nhật tăng dần.
+func (r *itemRemap) itemTransactionMap(itemTransactionMap map[int][]*models.Transaction) (map[int][]*models.Transaction, map[int]*models.Transaction) {
+	copies := make(map[*models.Transaction]*models.Transaction)
+	byTID := make(map[int]*models.Transaction)
+	dense := make(map[int][]*models.Transaction, len(r.original))
+	for index, item := range r.original {
+		transactions, found := itemTransactionMap[item]
+		if !found {
+			continue
+		}
+		list := make([]*models.Transaction, len(transactions))
+		for i, transaction := range transactions {
+			if copies[transaction] == nil {
+				copies[transaction] = r.transaction(transaction)
+				byTID[transaction.TID] = copies[transaction]
+			}
+			list[i] = copies[transaction]
+		}
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].TransactionUtility < list[j].TransactionUtility
+		})
+		dense[index] = list
+	}
+	return dense, byTID
+}
+
+// insertByUtility chèn giao dịch vào danh sách đã sắp tăng dần theo utility của giao dịch,
+// sau các giao dịch có cùng utility
+func insertByUtility(list []*models.Transaction, transaction *models.Transaction) []*models.Transaction {
+	i := sort.Search(len(list), func(i int) bool {
+		return list[i].TransactionUtility > transaction.TransactionUtility
+	})
+	list = append(list, nil)
+	copy(list[i+1:], list[i:])
+	list[i] = transaction
+	return list
+}
+
+// removeByUtility xóa giao dịch khỏi danh sách đã sắp tăng dần theo utility của giao dịch;
+// chỉ các giao dịch có cùng utility được so sánh
+func removeByUtility(list []*models.Transaction, transaction *models.Transaction) []*models.Transaction {
+	i := sort.Search(len(list), func(i int) bool {
+		return list[i].TransactionUtility >= transaction.TransactionUtility
+	})
+	for ; i < len(list) && list[i].TransactionUtility == transaction.TransactionUtility; i++ {
+		if list[i] == transaction {
+			return append(list[:i], list[i+1:]...)
+		}
+	}
+	return list
+}
+
+// scope đổi phạm vi tìm kiếm sang chỉ số; nil (toàn bộ cơ sở dữ liệu) được giữ nguyên
+func (r *itemRemap) scope(scope []map[int]bool) []map[int]bool {
+	if scope == nil {
+		return nil
+	}
+	dense := make([]map[int]bool, len(scope))
+	for i, items := range scope {
+		dense[i] = make(map[int]bool, len(items))
+		for item := range items {
+			if index, ok := r.dense[item]; ok {
+				dense[i][index] = true
+			}
+		}
+	}
+	return dense
+}
+
+// byItem sắp các item của giao dịch (cùng utility tương ứng) theo mã tăng dần
+type byItem struct{ *models.Transaction }
+
+func (t byItem) Len() int           { return len(t.Items) }
+func (t byItem) Less(i, j int) bool { return t.Items[i] < t.Items[j] }
+func (t byItem) Swap(i, j int) {
+	t.Items[i], t.Items[j] = t.Items[j], t.Items[i]
+	t.Utilities[i], t.Utilities[j] = t.Utilities[j], t.Utilities[i]
+}
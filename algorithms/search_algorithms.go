@@ -1,347 +1,252 @@
 package algorithms
 
 import (
+	"EMHUNer/logger"
 	"EMHUNer/models"
 	"EMHUNer/utility"
-	"fmt"
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
 )
 
 type SearchAlgorithms struct {
 	UtilityArray        *models.UtilityArray
-	Beta                map[int]bool
-	ItemList            []int
-	FilteredPrimary     []int
-	FilteredSecondary   []int
 	HighUtilityItemsets []*models.HighUtilityItemset
+	Logger              logger.Logger
+	TopK                int
+	Closed              bool // chỉ ghi nhận các HUI đóng
+	Maximal             bool // chỉ giữ các HUI cực đại và cắt tỉa các nhánh bị bao
+	Workers             int  // số goroutine của SearchParallel
+	SplitDepth          int  // số tầng nhánh được chia thành tác vụ song song (tối thiểu 1: các item Primary ở tầng đầu)
+	MaxTIDs             int  // số TID tối đa được giữ cho mỗi HUI; 0 là không thu thập, âm là không giới hạn
+	ItemNames           models.ItemNames
+	remap               *itemRemap                  // đổi chỉ số item của tìm kiếm về mã gốc (nil: itemset đã theo mã gốc)
+	transactionsByID    map[int]*models.Transaction // giao dịch gốc theo TID, dùng để kiểm tra itemset đóng
+	topK                topKHeap
+	scope               []map[int]bool
+	done                <-chan struct{} // kênh Done của context đang chạy (nil: không thể hủy)
+	completed           []int           // các item Primary ở tầng đầu có nhánh đã được duyệt hết
+	abort               context.CancelFunc
+	sink                Sink        // nhận HUI ngay khi tìm thấy thay vì giữ trong HighUtilityItemsets (nil: giữ lại)
+	sinkErr             error       // lỗi đầu tiên do sink trả về
+	emitted             int         // số HUI đã gửi vào sink
+	stats               SearchStats // được cập nhật nguyên tử vì các worker cùng ghi
+	mu                  sync.Mutex  // bảo vệ HighUtilityItemsets và topK khi tìm kiếm song song
 }
 
 func NewSearchAlgorithms(utilityArray *models.UtilityArray) *SearchAlgorithms {
 	return &SearchAlgorithms{
 		UtilityArray:        utilityArray,
-		Beta:                make(map[int]bool),
 		HighUtilityItemsets: []*models.HighUtilityItemset{},
+		Logger:              logger.Nop(),
 	}
 }
+
+// Search duyệt tuần tự các nhánh X ∪ {item} với item thuộc primary, trong đó X rỗng và itemTransactionMap
+// là danh sách giao dịch của từng item (item trong giao dịch tăng dần theo thứ tự xử lý).
+// Mỗi nhánh ở tầng này được theo dõi như một nhánh gốc để biết nhánh nào đã hoàn tất khi bị hủy.
 func (s *SearchAlgorithms) Search(eta []int, X map[int]bool, itemTransactionMap map[int][]*models.Transaction, primary []int, secondary []int, minU float64) {
-	if len(primary) == 0 {
-		return
+	for _, item := range primary {
+		if s.cancelled() {
+			return
+		}
+		minU = s.raiseThreshold(minU)
+		task := rootTask(X, item, itemTransactionMap, secondary)
+		s.searchItem(task, eta, minU, nil)
+		s.finishTask(task)
 	}
+}
+
+// rootTask tạo tác vụ của nhánh tầng đầu {item}. Chỉ các giao dịch chứa item mới được đưa vào
+// cơ sở dữ liệu chiếu ban đầu, vì các giao dịch khác sẽ bị loại ngay khi chiếu theo item.
+func rootTask(X map[int]bool, item int, itemTransactionMap map[int][]*models.Transaction, secondary []int) searchTask {
+	return searchTask{x: X, item: item, projection: models.NewProjection(itemTransactionMap[item]), secondary: secondary, root: newBranchProgress(item)}
+}
 
+// searchFrom duyệt tuần tự các nhánh con của X trên cơ sở dữ liệu chiếu của X; root là nhánh gốc chứa X
+func (s *SearchAlgorithms) searchFrom(eta []int, X map[int]bool, projection models.Projection, primary []int, secondary []int, minU float64, root *branchProgress, depth int) {
 	for _, item := range primary {
-		// Sao chép X vào Beta và thêm item
-		s.Beta = copyMap(X)
-		s.Beta[item] = true
-		s.ItemList = mapKeys(s.Beta)
+		if s.cancelled() {
+			return
+		}
+		minU = s.raiseThreshold(minU)
+		s.searchItem(searchTask{x: X, item: item, projection: projection, secondary: secondary, depth: depth, root: root}, eta, minU, nil)
+	}
+}
+
+// searchItem xử lý nhánh β = X ∪ {item}: ghi nhận β nếu là HUI, mở rộng bằng các item η rồi đệ quy
+// với các item thứ cấp đạt RSU/RLU. Mọi trạng thái của nút là biến cục bộ nên các nhánh khác nhau có
+// thể chạy đồng thời. Khi spawn khác nil, các nhánh con được giao cho worker pool thay vì đệ quy.
+func (s *SearchAlgorithms) searchItem(task searchTask, eta []int, minU float64, spawn func(searchTask)) {
+	item, secondary := task.item, task.secondary
+	if s.cancelled() {
+		return
+	}
 
-		// Tạo projectedItemTransactionMap và tính utility của Beta trong cùng một bước
-		projectedItemTransactionMap, utilityBeta := s.createProjectedItemTransactionMapAndCalculateUtility(itemTransactionMap, s.ItemList)
+	// Sao chép X vào beta và thêm item
+	beta := copyMap(task.x)
+	beta[item] = true
+	itemList := mapKeys(beta)
+	if !s.inScope(itemList) {
+		return
+	}
+	s.countNode(len(itemList), false)
+
+	// Chiếu cơ sở dữ liệu theo item và tính utility của beta trong cùng một bước
+	projection, utilityBeta, occ := s.project(task.projection, item)
+	if utilityBeta >= minU {
+		s.recordHUI(itemList, utilityBeta, occ, projection)
+	}
+	if s.Logger.Enabled(logger.Node) {
 		if utilityBeta >= minU {
-			fmt.Printf("U(%d) = %.2f >= %.2f HUI Found: %v\n", item, utilityBeta, minU, s.Beta)
-			s.HighUtilityItemsets = append(s.HighUtilityItemsets, models.NewHighUtilityItemset(s.ItemList, utilityBeta))
+			s.Logger.Logf(logger.Node, "U(%d) = %.2f >= %.2f HUI Found: %v\n", s.originalItem(item), utilityBeta, minU, s.remap.originalItems(itemList))
 		} else {
-			fmt.Printf("%.2f < %.2f so %v is not a HUI.\n", utilityBeta, minU, s.Beta)
+			s.Logger.Logf(logger.Node, "%.2f < %.2f so %v is not a HUI.\n", utilityBeta, minU, s.remap.originalItems(itemList))
 		}
+	}
+	minU = s.raiseThreshold(minU)
 
-		// Đệ quy với projectedItemTransactionMap thay vì itemTransactionMap đầy đủ
-		if utilityBeta > minU {
-			s.SearchN(eta, s.Beta, projectedItemTransactionMap, minU)
-		}
+	// Ở chế độ maximal, bỏ qua nhánh khi mọi itemset trong đó đều nằm trong một HUI đã tìm thấy
+	if s.Maximal && s.branchSubsumed(itemList, projection, secondary[indexOf(secondary, item)+1:], eta) {
+		s.Logger.Logf(logger.Node, "Branch of %v is subsumed, pruned.\n", s.remap.originalItems(itemList))
+		atomic.AddInt64(&s.stats.PrunedSubsumed, 1)
+		return
+	}
 
-		// Tạo FilteredPrimary và FilteredSecondary dựa trên RSU và RLU
-		s.FilteredPrimary = []int{}
-		s.FilteredSecondary = []int{}
-		utility.CalculateRSUForAllItem(projectedItemTransactionMap, s.ItemList, secondary, s.UtilityArray)
-		utility.CalculateRLUForAllItem(projectedItemTransactionMap, s.ItemList, secondary, s.UtilityArray)
-		// In ra RSU và RLU để kiểm tra
-		for i, secItem := range secondary {
-			if secItem == item {
-				continue
-			}
-			if i > indexOf(secondary, item) {
-				rsu := s.UtilityArray.GetRSU(secItem)
-				rlu := s.UtilityArray.GetRLU(secItem)
+	// Đệ quy với cơ sở dữ liệu chiếu của β thay vì cơ sở dữ liệu đầy đủ.
+	// Thêm item η làm giảm utility trong từng giao dịch nhưng có thể loại bỏ các giao dịch mà β
+	// có utility âm, nên cận đúng là tổng utility dương của β chứ không phải utilityBeta.
+	if projection.PositiveUtility() >= minU {
+		s.SearchN(eta, beta, projection, minU)
+	}
 
-				if rsu >= minU {
-					s.FilteredPrimary = append(s.FilteredPrimary, secItem)
-				}
-				if rlu >= minU {
-					s.FilteredSecondary = append(s.FilteredSecondary, secItem)
-				}
-			}
+	// Tạo filteredPrimary và filteredSecondary dựa trên RSU và RLU.
+	// RSU/RLU của nút được tính vào một UtilityArray riêng (theo vị trí trong candidates) để không ghi đè lên nút khác.
+	var filteredPrimary, filteredSecondary []int
+	candidates := secondary[indexOf(secondary, item)+1:]
+	bounds := models.NewBoundArray(len(candidates))
+	utility.CalculateBoundsForAllItem(projection, candidates, bounds, true)
+	var prunedRSU, prunedRLU int64
+	for i, secItem := range candidates {
+		if bounds.GetRSU(i) >= minU {
+			filteredPrimary = append(filteredPrimary, secItem)
+		} else {
+			prunedRSU++
 		}
+		if bounds.GetRLU(i) >= minU {
+			filteredSecondary = append(filteredSecondary, secItem)
+		} else {
+			prunedRLU++
+		}
+	}
+	atomic.AddInt64(&s.stats.PrunedRSU, prunedRSU)
+	atomic.AddInt64(&s.stats.PrunedRLU, prunedRLU)
 
-		// Đệ quy gọi lại Search với `projectedItemTransactionMap` đã thu hẹp
-		s.Search(eta, s.Beta, projectedItemTransactionMap, s.FilteredPrimary, s.FilteredSecondary, minU)
+	// Đệ quy (hoặc giao cho worker pool) với cơ sở dữ liệu chiếu đã thu hẹp
+	if spawn != nil && task.depth+1 < s.SplitDepth {
+		for _, child := range filteredPrimary {
+			spawn(searchTask{x: beta, item: child, projection: projection, secondary: filteredSecondary, depth: task.depth + 1, root: task.root})
+		}
+		return
 	}
+	s.searchFrom(eta, beta, projection, filteredPrimary, filteredSecondary, minU, task.root, task.depth+1)
 }
 
-func (s *SearchAlgorithms) SearchN(eta []int, beta map[int]bool, itemTransactionMap map[int][]*models.Transaction, minU float64) {
+// SearchN mở rộng beta bằng các item η trên cơ sở dữ liệu chiếu của beta
+func (s *SearchAlgorithms) SearchN(eta []int, beta map[int]bool, projection models.Projection, minU float64) {
 	if len(eta) == 0 {
 		return
 	}
 
 	for _, item := range eta {
+		if s.cancelled() {
+			return
+		}
 		// Sao chép beta vào betaNew và thêm item
 		betaNew := copyMap(beta)
 		betaNew[item] = true
 		itemList := mapKeys(betaNew)
+		if !s.inScope(itemList) {
+			continue
+		}
+		s.countNode(len(itemList), true)
 
-		// Tạo projectedItemTransactionMap và tính utility của betaNew trong cùng một bước
-		projectedDBNew, utilityBetaNew := s.createProjectedItemTransactionMapAndCalculateUtility(itemTransactionMap, itemList)
+		// Chiếu cơ sở dữ liệu theo item và tính utility của betaNew trong cùng một bước
+		projectedDBNew, utilityBetaNew, occ := s.project(projection, item)
 
 		if utilityBetaNew >= minU {
-			fmt.Printf("U(%d) = %.2f >= %.2f HUI Found: %v\n", item, utilityBetaNew, minU, betaNew)
-			s.HighUtilityItemsets = append(s.HighUtilityItemsets, models.NewHighUtilityItemset(itemList, utilityBetaNew))
-		} else {
-			fmt.Printf("%.2f < %.2f so %v is not a HUI.\n", utilityBetaNew, minU, betaNew)
+			s.recordHUI(itemList, utilityBetaNew, occ, projectedDBNew)
 		}
+		if s.Logger.Enabled(logger.Node) {
+			if utilityBetaNew >= minU {
+				s.Logger.Logf(logger.Node, "U(%d) = %.2f >= %.2f HUI Found: %v\n", s.originalItem(item), utilityBetaNew, minU, s.remap.originalItems(itemList))
+			} else {
+				s.Logger.Logf(logger.Node, "%.2f < %.2f so %v is not a HUI.\n", utilityBetaNew, minU, s.remap.originalItems(itemList))
+			}
+		}
+		minU = s.raiseThreshold(minU)
 
 		// Tạo FilteredPrimary dựa trên RSU
 		itemIndex := indexOf(eta, item)
+		if s.Maximal && s.branchSubsumed(itemList, projectedDBNew, eta[itemIndex+1:]) {
+			s.Logger.Logf(logger.Node, "Branch of %v is subsumed, pruned.\n", s.remap.originalItems(itemList))
+			atomic.AddInt64(&s.stats.PrunedSubsumed, 1)
+			continue
+		}
 		filteredPrimary := []int{}
-		utility.CalculateRSUForAllItem(projectedDBNew, itemList, eta, s.UtilityArray)
-
-		for _, secItem := range eta {
-			if secItem == item {
-				continue
-			}
-			if indexOf(eta, secItem) > itemIndex {
-				rsu := s.UtilityArray.GetRSU(secItem)
-				if rsu >= minU {
-					filteredPrimary = append(filteredPrimary, secItem)
-				}
+		candidates := eta[itemIndex+1:]
+		bounds := models.NewBoundArray(len(candidates))
+		utility.CalculateBoundsForAllItem(projectedDBNew, candidates, bounds, false)
+
+		for i, secItem := range candidates {
+			if bounds.GetRSU(i) >= minU {
+				filteredPrimary = append(filteredPrimary, secItem)
+			} else {
+				atomic.AddInt64(&s.stats.PrunedRSU, 1)
 			}
 		}
-		fmt.Printf("Primary = %v\n", filteredPrimary)
+		if s.Logger.Enabled(logger.Node) {
+			s.Logger.Logf(logger.Node, "Primary = %v\n", s.remap.originalItems(filteredPrimary))
+		}
 
-		// Đệ quy gọi lại SearchN với projectedItemTransactionMap đã thu hẹp
+		// Đệ quy gọi lại SearchN với cơ sở dữ liệu chiếu đã thu hẹp
 		s.SearchN(filteredPrimary, betaNew, projectedDBNew, minU)
 	}
 }
 
-func (s *SearchAlgorithms) createProjectedItemTransactionMapAndCalculateUtility(itemTransactionMap map[int][]*models.Transaction, items []int) (map[int][]*models.Transaction, float64) {
-	projectedItemTransactionMap := make(map[int][]*models.Transaction)
+// project tạo cơ sở dữ liệu chiếu của X ∪ {item} từ cơ sở dữ liệu chiếu của X mà không sao chép giao dịch:
+// mỗi giao dịch chứa item được giữ bằng con trỏ, cùng vị trí ngay sau item và utility của X ∪ {item}.
+// Item đứng sau mọi item của X nên chỉ cần tìm nó từ Offset trở đi. Đồng thời tính utility của
+// X ∪ {item} và tóm tắt các lần xuất hiện (support, utility nhỏ nhất/lớn nhất trong một giao dịch).
+func (s *SearchAlgorithms) project(projection models.Projection, item int) (models.Projection, float64, occurrences) {
+	var projected models.Projection
 	totalUtility := 0.0
+	var occ occurrences
 
-	// Duyệt qua từng item trong items
-	for _, item := range items {
-		transactions, found := itemTransactionMap[item]
-		if !found {
+	for _, entry := range projection {
+		items := entry.Transaction.Items[entry.Offset:]
+		index := sort.SearchInts(items, item)
+		if index == len(items) || items[index] != item {
 			continue
 		}
-
-		// Duyệt qua từng giao dịch chứa item
-		for _, transaction := range transactions {
-			// Kiểm tra nếu giao dịch chứa tất cả các items trong danh sách `items`
-			if containsAllItems(transaction.Items, items) {
-				var projectedItems []int
-				var projectedUtilities []float64
-				transactionUtility := 0.0
-
-				// Lấy danh sách items và utilities từ transaction
-				for i := 0; i < len(transaction.Items); i++ {
-					projectedItems = append(projectedItems, transaction.Items[i])
-					projectedUtilities = append(projectedUtilities, transaction.Utilities[i])
-				}
-
-				// Tính tổng utility cho tất cả các items trong danh sách `items`
-				for _, item := range items {
-					itemIndex := indexOf(transaction.Items, item)
-					if itemIndex != -1 {
-						transactionUtility += transaction.Utilities[itemIndex]
-					}
-				}
-
-				// Cộng thêm utility của giao dịch này vào `totalUtility`
-				totalUtility += transactionUtility
-
-				// Tạo transaction đã projected và thêm vào map
-				projectedTransaction := models.NewTransaction(projectedItems, projectedUtilities, transactionUtility)
-				projectedItemTransactionMap[item] = append(projectedItemTransactionMap[item], projectedTransaction)
-			}
-		}
+		position := entry.Offset + index
+		transactionUtility := entry.Utility + entry.Transaction.Utilities[position]
+		totalUtility += transactionUtility
+		occ.add(transactionUtility)
+		projected = append(projected, models.ProjectedTransaction{Transaction: entry.Transaction, Offset: position + 1, Utility: transactionUtility})
 	}
+	atomic.AddInt64(&s.stats.ProjectedTransactions, int64(len(projected)))
 
-	return projectedItemTransactionMap, totalUtility
-}
-
-// func (s *SearchAlgorithms) Search(eta []int, X map[int]bool, transactions []*models.Transaction, primary []int, secondary []int, minU float64) {
-// 	if len(primary) == 0 {
-// 		return
-// 	}
-
-// 	for _, item := range primary {
-
-// 		s.Beta = copyMap(X)
-// 		s.Beta[item] = true
-// 		s.ItemList = mapKeys(s.Beta)
-
-// 		projectedDB, utilityBeta := s.projectDatabase(transactions, s.ItemList)
-
-// 		if utilityBeta >= minU {
-// 			fmt.Printf("U(%d) = %.2f >= %.2f HUI Found: %v\n", item, utilityBeta, minU, s.Beta)
-// 			s.HighUtilityItemsets = append(s.HighUtilityItemsets, models.NewHighUtilityItemset(s.ItemList, utilityBeta))
-// 		} else {
-// 			fmt.Printf("%.2f < %.2f so %v is not a HUI.\n", utilityBeta, minU, s.Beta)
-// 		}
-
-// 		if utilityBeta > minU {
-// 			s.SearchN(eta, s.Beta, projectedDB, minU)
-// 		}
-
-// 		s.FilteredPrimary = []int{}
-// 		s.FilteredSecondary = []int{}
-// 		utility.CalculateRSUForAllItem(projectedDB, s.ItemList, secondary, s.UtilityArray)
-// 		utility.CalculateRLUForAllItem(projectedDB, s.ItemList, secondary, s.UtilityArray)
-// 		for i, secItem := range secondary {
-
-// 			if secItem == item {
-// 				continue
-// 			}
-
-// 			if i > indexOf(secondary, item) {
-// 				rsu := s.UtilityArray.GetRSU(secItem)
-// 				rlu := s.UtilityArray.GetRLU(secItem)
-
-// 				if rsu >= minU {
-// 					s.FilteredPrimary = append(s.FilteredPrimary, secItem)
-// 				}
-// 				if rlu >= minU {
-// 					s.FilteredSecondary = append(s.FilteredSecondary, secItem)
-// 				}
-// 			}
-// 		}
-
-// 		fmt.Printf("Beta= %v\n", s.Beta)
-// 		fmt.Printf("Primary%v = %v\n", s.ItemList, s.FilteredPrimary)
-// 		fmt.Printf("Secondary%v = %v\n", s.ItemList, s.FilteredSecondary)
-
-// 		s.Search(eta, s.Beta, projectedDB, s.FilteredPrimary, s.FilteredSecondary, minU)
-// 	}
-// }
-
-// func (s *SearchAlgorithms) SearchN(eta []int, beta map[int]bool, transactions []*models.Transaction, minU float64) {
-// 	if len(eta) == 0 {
-// 		return
-// 	}
-
-// 	for _, item := range eta {
-// 		betaNew := copyMap(beta)
-// 		betaNew[item] = true
-
-// 		itemList := mapKeys(betaNew)
-
-// 		projectedDBNew, utilityBetaNew := s.projectDatabase(transactions, itemList)
-
-// 		if utilityBetaNew >= minU {
-// 			fmt.Printf("U(%d) = %.2f >= %.2f HUI Found: %v\n", item, utilityBetaNew, minU, betaNew)
-// 			s.HighUtilityItemsets = append(s.HighUtilityItemsets, models.NewHighUtilityItemset(mapKeys(betaNew), utilityBetaNew))
-// 		} else {
-// 			fmt.Printf("%.2f < %.2f so %v is not a HUI.\n", utilityBetaNew, minU, betaNew)
-// 		}
-
-// 		itemIndex := indexOf(eta, item)
-// 		filteredPrimary := []int{}
-// 		utility.CalculateRSUForAllItem(projectedDBNew, itemList, eta, s.UtilityArray)
-// 		for _, secItem := range eta {
-// 			if secItem == item {
-// 				continue
-// 			}
-// 			if indexOf(eta, secItem) > itemIndex {
-// 				rsu := s.UtilityArray.GetRSU(secItem)
-// 				if rsu >= minU {
-// 					filteredPrimary = append(filteredPrimary, secItem)
-// 				}
-// 			}
-// 		}
-// 		fmt.Printf("Primary = %v\n", filteredPrimary)
-// 		s.SearchN(filteredPrimary, betaNew, projectedDBNew, minU)
-// 	}
-// }
-
-// func (s *SearchAlgorithms) projectDatabase(transactions []*models.Transaction, items []int) []*models.Transaction {
-// 	var projectedDB []*models.Transaction
-
-// 	for _, transaction := range transactions {
-// 		if containsAllItems(transaction.Items, items) {
-// 			var projectedItems []int
-// 			var projectedUtilities []float64
-// 			lastItemIndex := -1
-
-// 			for _, item := range items {
-// 				itemIndex := indexOf(transaction.Items, item)
-// 				if itemIndex > lastItemIndex {
-// 					lastItemIndex = itemIndex
-// 				}
-// 			}
-
-// 			for i := lastItemIndex + 1; i < len(transaction.Items); i++ {
-// 				projectedItems = append(projectedItems, transaction.Items[i])
-// 				projectedUtilities = append(projectedUtilities, transaction.Utilities[i])
-// 			}
-
-// 			if len(projectedItems) > 0 {
-// 				projectedDB = append(projectedDB, models.NewTransaction(projectedItems, projectedUtilities, calculateTransactionUtility(projectedUtilities)))
-// 			}
-// 		}
-// 	}
-
-//		return projectedDB
-//	}
-func (s *SearchAlgorithms) projectDatabase(transactions []*models.Transaction, items []int) ([]*models.Transaction, float64) {
-	var projectedDB []*models.Transaction
-	totalUtility := 0.0
-
-	for _, transaction := range transactions {
-		if containsAllItems(transaction.Items, items) {
-			var projectedItems []int
-			var projectedUtilities []float64
-			lastItemIndex := -1
-
-			for _, item := range items {
-				itemIndex := indexOf(transaction.Items, item)
-				if itemIndex > lastItemIndex {
-					lastItemIndex = itemIndex
-				}
-			}
-
-			for i := 0; i < len(transaction.Items); i++ {
-				projectedItems = append(projectedItems, transaction.Items[i])
-				projectedUtilities = append(projectedUtilities, transaction.Utilities[i])
-			}
-
-			if len(projectedItems) > 0 {
-				transactionUtility := calculateTransactionUtility(projectedUtilities)
-				projectedDB = append(projectedDB, models.NewTransaction(projectedItems, projectedUtilities, transactionUtility))
-
-				for _, item := range items {
-					index := indexOf(transaction.Items, item)
-					if index != -1 {
-						totalUtility += transaction.Utilities[index]
-					}
-				}
-			}
-		}
-	}
-	return projectedDB, totalUtility
+	return projected, totalUtility, occ
 }
 
-func (s *SearchAlgorithms) calculateUtility(transactions []*models.Transaction, itemset map[int]bool) float64 {
-	totalUtility := 0.0
-
-	for _, transaction := range transactions {
-		if containsAllItemsMap(transaction.Items, itemset) {
-			for item := range itemset {
-				index := indexOf(transaction.Items, item)
-				if index != -1 {
-					itemUtility := transaction.Utilities[index]
-					totalUtility += itemUtility
-				}
-			}
-		}
+// originalItem trả về mã gốc của một item của tìm kiếm
+func (s *SearchAlgorithms) originalItem(item int) int {
+	if s.remap == nil {
+		return item
 	}
-
-	return totalUtility
+	return s.remap.original[item]
 }
 
 func copyMap(original map[int]bool) map[int]bool {
@@ -378,34 +283,15 @@ func indexOf(items []int, item int) int {
 	return -1
 }
 
-func containsAllItemsMap(items []int, itemset map[int]bool) bool {
-	for item := range itemset {
-		if indexOf(items, item) == -1 {
-			return false
-		}
-	}
-	return true
-}
-
-//	func (s *SearchAlgorithms) printProjectedDatabase(projectedDB []*models.Transaction, items []int) {
-//		fmt.Printf("\nProjected Database after items %v:\n", items)
-//		for _, transaction := range projectedDB {
-//			fmt.Printf("Items: %v, Utilities: %v, Transaction Utility: %.2f\n",
-//				transaction.Items, transaction.Utilities, calculateTransactionUtility(transaction.Utilities))
-//		}
-//		fmt.Println("----------------------------------")
-//	}
-func (s *SearchAlgorithms) printProjectedDatabase(projectedItemTransactionMap map[int][]*models.Transaction) {
-	fmt.Println("\nProjected Item Transaction Map:")
+func (s *SearchAlgorithms) printProjectedDatabase(projection models.Projection) {
+	s.Logger.Logf(logger.Node, "Projected Database:\n")
 
-	for item, transactions := range projectedItemTransactionMap {
-		fmt.Printf("Item %d:\n", item)
-		for _, transaction := range transactions {
-			fmt.Printf("  Items: %v, Utilities: %v, Transaction Utility: %.2f\n",
-				transaction.Items, transaction.Utilities, transaction.TransactionUtility)
-		}
-		fmt.Println("----------------------------------")
+	for _, entry := range projection {
+		transaction := entry.Transaction
+		s.Logger.Logf(logger.Node, "  Items: %v, Utilities: %v, Utility of X: %.2f\n",
+			s.remap.originalItems(transaction.Items[entry.Offset:]), transaction.Utilities[entry.Offset:], entry.Utility)
 	}
+	s.Logger.Logf(logger.Node, "----------------------------------\n")
 }
 
 func calculateTransactionUtility(utilities []float64) float64 { // Chuyển sang float64
@@ -0,0 +1,102 @@
+package algorithms
+
+import (
+	"EMHUNer/models"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Sink nhận từng HUI ngay khi thuật toán xác nhận nó. Các lần gọi Emit được tuần tự hóa, kể cả khi
+// tìm kiếm song song, nên Sink không cần an toàn đồng thời. Khi Emit trả về lỗi, tìm kiếm dừng lại.
+type Sink interface {
+	Emit(hui *models.HighUtilityItemset) error
+}
+
+// SinkFunc cho phép dùng một hàm callback làm Sink
+type SinkFunc func(hui *models.HighUtilityItemset) error
+
+func (f SinkFunc) Emit(hui *models.HighUtilityItemset) error {
+	return f(hui)
+}
+
+// CollectSink giữ mọi HUI nhận được trong bộ nhớ theo thứ tự tìm thấy
+type CollectSink struct {
+	HighUtilityItemsets []*models.HighUtilityItemset
+}
+
+func NewCollectSink() *CollectSink {
+	return &CollectSink{}
+}
+
+func (c *CollectSink) Emit(hui *models.HighUtilityItemset) error {
+	c.HighUtilityItemsets = append(c.HighUtilityItemsets, hui)
+	return nil
+}
+
+// CounterSink chỉ đếm số HUI và bỏ qua nội dung, dùng để đo hiệu năng mà không tốn bộ nhớ cho kết quả
+type CounterSink struct {
+	Count   int
+	Utility float64 // tổng utility của các HUI đã đếm
+}
+
+func NewCounterSink() *CounterSink {
+	return &CounterSink{}
+}
+
+func (c *CounterSink) Emit(hui *models.HighUtilityItemset) error {
+	c.Count++
+	c.Utility += hui.Utility
+	return nil
+}
+
+// FileSink ghi mỗi HUI thành một dòng "Itemset: [...], Utility: ..." (kèm Support nếu WithSupport)
+// ngay khi nhận được, dùng tên item nếu HUI có từ điển tên. Người gọi nên truyền một writer có bộ đệm và tự Flush sau khi chạy xong.
+type FileSink struct {
+	w           io.Writer
+	WithSupport bool
+}
+
+func NewFileSink(w io.Writer, withSupport bool) *FileSink {
+	return &FileSink{w: w, WithSupport: withSupport}
+}
+
+func (f *FileSink) Emit(hui *models.HighUtilityItemset) error {
+	var err error
+	if f.WithSupport {
+		_, err = fmt.Fprintf(f.w, "Itemset: %s, Utility: %.2f, Support: %d\n", hui.Names.FormatItemset(hui.Itemset), hui.Utility, hui.Support)
+	} else {
+		_, err = fmt.Fprintf(f.w, "Itemset: %s, Utility: %.2f\n", hui.Names.FormatItemset(hui.Itemset), hui.Utility)
+	}
+	return err
+}
+
+// NewChannelSink trả về Sink gửi từng HUI vào ch. Việc gửi chờ người nhận, nên tốc độ tìm kiếm theo
+// kịp tốc độ tiêu thụ; khi ctx bị hủy, Emit trả về lỗi của ctx thay vì chờ mãi. Sink không đóng ch.
+func NewChannelSink(ctx context.Context, ch chan<- *models.HighUtilityItemset) Sink {
+	return SinkFunc(func(hui *models.HighUtilityItemset) error {
+		select {
+		case ch <- hui:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// emit chuẩn hóa và gửi một HUI vào sink; lỗi đầu tiên được giữ lại và dừng tìm kiếm.
+// Người gọi phải giữ s.mu.
+func (s *SearchAlgorithms) emit(hui *models.HighUtilityItemset) {
+	if s.sinkErr != nil {
+		return
+	}
+	hui.Itemset = append([]int(nil), hui.Itemset...)
+	sort.Ints(hui.Itemset)
+	if err := s.sink.Emit(hui); err != nil {
+		s.sinkErr = err
+		s.abort()
+		return
+	}
+	s.emitted++
+}
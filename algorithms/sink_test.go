@@ -0,0 +1,141 @@
+package algorithms
+
+import (
+	"EMHUNer/models"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestSinkReceivesEveryHUI(t *testing.T) {
+	for seed := int64(0); seed < randomSeeds; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		transactions := randomDatabase(r, 5+r.Intn(20))
+		minUtility := float64(5 + r.Intn(40))
+		want := reference(t, transactions, minUtility)
+
+		emhun := NewEMHUN(cloneTransactions(transactions), minUtility)
+		if seed%2 == 1 {
+			emhun.Workers, emhun.SplitDepth = 3, 2
+		}
+		collect := NewCollectSink()
+		emhun.Sink = collect
+		result := emhun.Run()
+
+		name := fmt.Sprintf("seed %d", seed)
+		if result.Err != nil || result.Incomplete {
+			t.Fatalf("%s: unexpected failure: err=%v incomplete=%v", name, result.Err, result.Incomplete)
+		}
+		if result.HighUtilityItemsets.Len() != 0 {
+			t.Errorf("%s: streamed run kept %d HUIs in the result", name, result.HighUtilityItemsets.Len())
+		}
+		if result.Stats.HighUtilityItemsets != len(collect.HighUtilityItemsets) {
+			t.Errorf("%s: Stats reports %d HUIs, sink received %d", name, result.Stats.HighUtilityItemsets, len(collect.HighUtilityItemsets))
+		}
+		for _, hui := range collect.HighUtilityItemsets {
+			if !sort.IntsAreSorted(hui.Itemset) {
+				t.Errorf("%s: itemset %v is not canonical", name, hui.Itemset)
+			}
+		}
+		checkAgainstReference(t, name, collect.HighUtilityItemsets, want)
+
+		if _, err := emhun.AppendTransactions(randomDatabase(r, 2)); !errors.Is(err, ErrSinkIncremental) {
+			t.Errorf("%s: AppendTransactions after a streamed run returned %v, want ErrSinkIncremental", name, err)
+		}
+	}
+}
+
+func TestSinkReceivesFinalTopKAndMaximal(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	transactions := randomDatabase(r, 25)
+
+	for _, maximal := range []bool{false, true} {
+		emhun := NewEMHUNTopK(cloneTransactions(transactions), 5)
+		if maximal {
+			emhun = NewEMHUN(cloneTransactions(transactions), 20)
+			emhun.Maximal = true
+		}
+		collect := NewCollectSink()
+		emhun.Sink = collect
+		result := emhun.Run()
+
+		items := result.HighUtilityItemsets.Items()
+		if len(items) == 0 || len(collect.HighUtilityItemsets) != len(items) {
+			t.Fatalf("maximal=%v: sink received %d HUIs, result has %d", maximal, len(collect.HighUtilityItemsets), len(items))
+		}
+		for i := range items {
+			if collect.HighUtilityItemsets[i] != items[i] {
+				t.Errorf("maximal=%v: HUI %d delivered out of result order", maximal, i)
+			}
+		}
+	}
+}
+
+func TestSinkErrorStopsSearch(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	transactions := randomDatabase(r, 30)
+	errFull := errors.New("sink full")
+
+	for _, workers := range []int{1, 3} {
+		emhun := NewEMHUN(cloneTransactions(transactions), 5)
+		emhun.Workers = workers
+		calls := 0
+		emhun.Sink = SinkFunc(func(*models.HighUtilityItemset) error {
+			calls++
+			if calls == 3 {
+				return errFull
+			}
+			return nil
+		})
+		result := emhun.Run()
+
+		if !errors.Is(result.Err, errFull) || !result.Incomplete {
+			t.Fatalf("workers=%d: got err=%v incomplete=%v, want the sink error and an incomplete result", workers, result.Err, result.Incomplete)
+		}
+		if calls != 3 || result.Stats.HighUtilityItemsets != 2 {
+			t.Errorf("workers=%d: sink called %d times with %d HUIs accepted, want 3 calls and 2 accepted", workers, calls, result.Stats.HighUtilityItemsets)
+		}
+	}
+}
+
+func TestChannelAndFileSinks(t *testing.T) {
+	transactions := readTable3(t)
+	want := reference(t, transactions, 30)
+
+	ch := make(chan *models.HighUtilityItemset)
+	emhun := NewEMHUN(cloneTransactions(transactions), 30)
+	emhun.Sink = NewChannelSink(context.Background(), ch)
+	done := make(chan *Result, 1)
+	go func() {
+		done <- emhun.Run()
+		close(ch)
+	}()
+	var received []*models.HighUtilityItemset
+	for hui := range ch {
+		received = append(received, hui)
+	}
+	<-done
+	checkAgainstReference(t, "channel", received, want)
+
+	var buf bytes.Buffer
+	sink := NewFileSink(&buf, true)
+	hui := models.NewHighUtilityItemset([]int{1, 3}, 42)
+	hui.Support = 2
+	if err := sink.Emit(hui); err != nil {
+		t.Fatal(err)
+	}
+	if got, line := buf.String(), "Itemset: [1 3], Utility: 42.00, Support: 2\n"; got != line {
+		t.Errorf("file sink wrote %q, want %q", got, line)
+	}
+
+	// Người nhận bỏ đi: context bị hủy nên Emit không chờ mãi
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewChannelSink(ctx, make(chan *models.HighUtilityItemset)).Emit(hui); !errors.Is(err, context.Canceled) {
+		t.Errorf("channel sink with a cancelled context returned %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package algorithms
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SearchStats mô tả hình dạng cây tìm kiếm và hiệu quả cắt tỉa của một lần tìm kiếm.
+// Một nhánh bị cắt tỉa là một item ứng viên bị loại vì cận tương ứng nhỏ hơn minUtility.
+type SearchStats struct {
+	Nodes                 int64 // số nút được duyệt trong Search
+	NodesN                int64 // số nút được duyệt trong SearchN (mở rộng bằng item η)
+	PrunedRTWU            int64 // item của (ρ ∪ δ) bị loại khỏi Secondary vì RTWU
+	PrunedRSU             int64 // nhánh không được duyệt vì RSU (không thuộc Primary)
+	PrunedRLU             int64 // item không được giữ trong Secondary của nút con vì RLU
+	PrunedSubsumed        int64 // nhánh bị bỏ qua ở chế độ maximal vì nằm trong một HUI đã tìm thấy
+	ProjectedTransactions int64 // số giao dịch chiếu được tạo ra
+	MaxDepth              int64 // số item của itemset dài nhất được duyệt
+}
+
+// PhaseTimings là thời gian của từng giai đoạn trong Run
+type PhaseTimings struct {
+	Classification time.Duration // phân loại ρ/δ/η và dựng ItemTransactionMap
+	RTWU           time.Duration
+	Filtering      time.Duration // xác định Secondary và loại các item không cần thiết
+	Sorting        time.Duration // sắp xếp item trong giao dịch và sắp xếp giao dịch
+	RSU            time.Duration // tính RSU và xác định Primary
+	Search         time.Duration
+}
+
+// countNode ghi nhận một nút của Search (eta = false) hoặc SearchN (eta = true) có depth item
+func (s *SearchAlgorithms) countNode(depth int, eta bool) {
+	if eta {
+		atomic.AddInt64(&s.stats.NodesN, 1)
+	} else {
+		atomic.AddInt64(&s.stats.Nodes, 1)
+	}
+	for {
+		current := atomic.LoadInt64(&s.stats.MaxDepth)
+		if int64(depth) <= current || atomic.CompareAndSwapInt64(&s.stats.MaxDepth, current, int64(depth)) {
+			return
+		}
+	}
+}
+
+// PhaseObserver được báo mỗi khi Run hoặc một lần cập nhật tăng dần bắt đầu một giai đoạn
+// ("classification", "rtwu", "filtering", "sorting", "rsu", "search"), ví dụ để đo bộ nhớ theo giai đoạn
+type PhaseObserver interface {
+	StartPhase(name string)
+}
+
+// timePhase báo cho PhaseObserver (nếu có) rồi cộng thời gian chạy của f vào *phase
+func (e *EMHUN) timePhase(name string, phase *time.Duration, f func()) {
+	if e.PhaseObserver != nil {
+		e.PhaseObserver.StartPhase(name)
+	}
+	start := time.Now()
+	f()
+	*phase += time.Since(start)
+}
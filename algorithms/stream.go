@@ -0,0 +1,219 @@
+package algorithms
+
+import (
+	"EMHUNer/dataset"
+	"EMHUNer/logger"
+	"EMHUNer/models"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// StreamMiner duy trì tập HUI trên một cửa sổ trượt gồm N giao dịch (hoặc N lô) gần nhất.
+// Mỗi lô mới được thêm vào và các giao dịch hết hạn được xóa qua ApplyUpdate, nên phân loại
+// ρ/δ/η, RTWU, ItemTransactionMap và cơ sở dữ liệu tìm kiếm được cập nhật trực tiếp thay vì khai thác lại
+// từ đầu; các giao dịch hết hạn được gỡ theo TID mà không sao chép lại cửa sổ.
+type StreamMiner struct {
+	WindowSize      int     // số giao dịch (hoặc số lô nếu WindowByBatch) được giữ trong cửa sổ
+	WindowByBatch   bool    // cửa sổ tính theo lô thay vì theo giao dịch
+	MinUtility      float64 // ngưỡng tuyệt đối, dùng khi MinUtilityRatio = 0
+	MinUtilityRatio float64 // ngưỡng tương đối so với tổng utility dương của cửa sổ
+	Closed          bool    // chỉ duy trì các HUI đóng
+	Maximal         bool    // chỉ duy trì các HUI cực đại
+	Logger          logger.Logger
+
+	miner   *EMHUN
+	batches [][]int // TID của từng lô trong cửa sổ, lô cũ nhất đứng đầu
+	size    int     // số giao dịch hiện có trong cửa sổ
+}
+
+// NewStreamMiner tạo miner với cửa sổ windowSize giao dịch và ngưỡng tuyệt đối minUtility
+func NewStreamMiner(windowSize int, minUtility float64) *StreamMiner {
+	return &StreamMiner{
+		WindowSize: windowSize,
+		MinUtility: minUtility,
+		Logger:     logger.Nop(),
+	}
+}
+
+// NewStreamMinerWithRatio tạo miner với ngưỡng được tính lại theo tổng utility dương của cửa sổ
+func NewStreamMinerWithRatio(windowSize int, ratio float64) *StreamMiner {
+	s := NewStreamMiner(windowSize, 0)
+	s.MinUtilityRatio = ratio
+	return s
+}
+
+// Miner trả về EMHUN đang giữ cửa sổ hiện tại (nil trước lô đầu tiên)
+func (s *StreamMiner) Miner() *EMHUN {
+	return s.miner
+}
+
+// Push thêm một lô giao dịch vào cửa sổ, loại các giao dịch hết hạn và trả về thay đổi của tập HUI.
+// Lô đầu tiên được khai thác bằng Run; mọi HUI tìm được được báo là Added.
+func (s *StreamMiner) Push(batch []*models.Transaction) (*Delta, error) {
+	if s.WindowSize <= 0 {
+		return nil, fmt.Errorf("algorithms: stream window size must be positive, got %d", s.WindowSize)
+	}
+	if len(batch) == 0 {
+		return nil, nil
+	}
+
+	// Ở chế độ theo giao dịch, phần đầu của một lô dài hơn cửa sổ sẽ hết hạn ngay nên bị bỏ qua
+	if !s.WindowByBatch && len(batch) > s.WindowSize {
+		batch = batch[len(batch)-s.WindowSize:]
+	}
+	if s.miner == nil {
+		return s.start(batch), nil
+	}
+
+	// Cửa sổ chỉ được trượt khi ApplyUpdate thành công, để một lô bị từ chối không làm cửa sổ quên
+	// các TID vẫn còn trong miner
+	expired, remaining := s.expire(batch)
+	delta, err := s.miner.ApplyUpdate(Update{Append: batch, Delete: expired})
+	if err != nil {
+		return nil, err
+	}
+	s.batches = remaining
+	s.size -= len(expired)
+	s.track(batch)
+	return delta, nil
+}
+
+// start khai thác lô đầu tiên bằng Run
+func (s *StreamMiner) start(batch []*models.Transaction) *Delta {
+	transactions := append([]*models.Transaction(nil), batch...)
+
+	if s.MinUtilityRatio > 0 {
+		s.miner = NewEMHUNWithRatio(transactions, s.MinUtilityRatio)
+	} else {
+		s.miner = NewEMHUN(transactions, s.MinUtility)
+	}
+	s.miner.Closed = s.Closed
+	s.miner.Maximal = s.Maximal
+	s.miner.SetLogger(s.Logger)
+	result := s.miner.Run()
+	s.track(batch)
+
+	return &Delta{
+		Added:  append([]*models.HighUtilityItemset(nil), result.HighUtilityItemsets.Items()...),
+		Result: result,
+	}
+}
+
+// expire trả về TID của các giao dịch phải rời cửa sổ khi lô mới được thêm vào và các lô còn lại
+// sau khi loại chúng, không thay đổi cửa sổ hiện tại
+func (s *StreamMiner) expire(batch []*models.Transaction) ([]int, [][]int) {
+	var expired []int
+	remaining := s.batches
+	if s.WindowByBatch {
+		for len(remaining) >= s.WindowSize {
+			expired = append(expired, remaining[0]...)
+			remaining = remaining[1:]
+		}
+		return expired, remaining
+	}
+
+	overflow := s.size + len(batch) - s.WindowSize
+	for overflow > 0 && len(remaining) > 0 {
+		oldest := remaining[0]
+		n := len(oldest)
+		if n > overflow {
+			n = overflow
+		}
+		expired = append(expired, oldest[:n]...)
+		remaining = remaining[1:]
+		if n < len(oldest) {
+			// Lô cũ nhất chỉ hết hạn một phần: dùng mảng mới để không ghi đè phần tử của s.batches
+			remaining = append([][]int{oldest[n:]}, remaining...)
+		}
+		overflow -= n
+	}
+	return expired, remaining
+}
+
+// track ghi nhận TID của lô vừa được thêm (TID được gán trong Run/ApplyUpdate)
+func (s *StreamMiner) track(batch []*models.Transaction) {
+	tids := make([]int, len(batch))
+	for i, transaction := range batch {
+		tids[i] = transaction.TID
+	}
+	s.batches = append(s.batches, tids)
+	s.size += len(tids)
+}
+
+// Consume đọc giao dịch từ kênh in, gom thành lô slide giao dịch và gọi emit với thay đổi
+// của tập HUI sau mỗi lần trượt cửa sổ. Lô cuối chưa đủ slide giao dịch được xử lý khi kênh đóng.
+func (s *StreamMiner) Consume(in <-chan *models.Transaction, slide int, emit func(*Delta) error) error {
+	if slide <= 0 {
+		return fmt.Errorf("algorithms: stream slide must be positive, got %d", slide)
+	}
+
+	batch := make([]*models.Transaction, 0, slide)
+	flush := func() error {
+		delta, err := s.Push(batch)
+		batch = make([]*models.Transaction, 0, slide)
+		if err != nil || delta == nil {
+			return err
+		}
+		return emit(delta)
+	}
+
+	for transaction := range in {
+		batch = append(batch, transaction)
+		if len(batch) == slide {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// ConsumeReader đọc giao dịch theo định dạng items:TU:utilities từ r và xử lý như Consume.
+// Dòng sai định dạng (kể cả dòng trống) bị bỏ qua; các lỗi kiểm tra khác của dataset.ParseLine
+// dừng luồng kèm số dòng.
+func (s *StreamMiner) ConsumeReader(r io.Reader, slide int, emit func(*Delta) error) error {
+	in := make(chan *models.Transaction)
+	done := make(chan struct{})
+	readErr := make(chan error, 1)
+
+	go func() {
+		defer close(in)
+		lines := dataset.NewLineReader(r, dataset.DefaultBufferSize)
+		lineNumber := 0
+		for {
+			line, err := lines.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				readErr <- fmt.Errorf("line %d: %w", lineNumber+1, err)
+				return
+			}
+			lineNumber++
+			transaction, err := dataset.ParseLine(line)
+			var parseErr *dataset.ParseError
+			if errors.As(err, &parseErr) && parseErr.Kind == dataset.KindFormat {
+				s.Logger.Logf(logger.Summary, "Invalid line format at line %d, skipped\n", lineNumber)
+				continue
+			}
+			if err != nil {
+				readErr <- fmt.Errorf("line %d: %w", lineNumber, err)
+				return
+			}
+			select {
+			case in <- transaction:
+			case <-done:
+				return
+			}
+		}
+		readErr <- nil
+	}()
+
+	err := s.Consume(in, slide, emit)
+	close(done)
+	if err != nil {
+		return err
+	}
+	return <-readErr
+}
@@ -0,0 +1,131 @@
+package algorithms
+
+import (
+	"EMHUNer/logger"
+	"EMHUNer/models"
+	"container/heap"
+	"math"
+	"sort"
+)
+
+// minPositiveUtility là ngưỡng nhỏ nhất dùng ở chế độ top-k: chỉ các itemset có utility dương mới được giữ
+const minPositiveUtility = math.SmallestNonzeroFloat64
+
+// topKHeap là min-heap theo utility, giữ k HUI tốt nhất tìm được đến hiện tại
+type topKHeap []*models.HighUtilityItemset
+
+func (h topKHeap) Len() int { return len(h) }
+
+// Less xếp itemset có utility thấp hơn lên đầu heap; khi utility bằng nhau, itemset đứng sau theo
+// thứ tự khóa bị loại trước, nên tập top-k không phụ thuộc thứ tự tìm thấy (kể cả khi tìm kiếm song song)
+func (h topKHeap) Less(i, j int) bool {
+	if h[i].Utility != h[j].Utility {
+		return h[i].Utility < h[j].Utility
+	}
+	return itemsetKey(h[i].Itemset) > itemsetKey(h[j].Itemset)
+}
+func (h topKHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *topKHeap) Push(x interface{}) { *h = append(*h, x.(*models.HighUtilityItemset)) }
+func (h *topKHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// NewEMHUNTopK tạo EMHUN ở chế độ top-k: trả về k itemset có utility cao nhất mà không cần minUtility.
+// Ngưỡng nội bộ được khởi tạo từ utility của các item đơn và nâng dần trong quá trình tìm kiếm.
+func NewEMHUNTopK(transactions []*models.Transaction, k int) *EMHUN {
+	e := NewEMHUN(transactions, 0)
+	e.TopK = k
+	return e
+}
+
+// initialTopKThreshold trả về utility lớn thứ k trong các item đơn thuộc (ρ ∪ δ).
+// Vì đã có ít nhất k itemset đạt utility này nên có thể dùng nó làm ngưỡng cắt tỉa ban đầu.
+func (e *EMHUN) initialTopKThreshold(combinedSet map[int]bool) float64 {
+	var utilities []float64
+	for item := range combinedSet {
+		itemUtility := 0.0
+		for _, transaction := range e.ItemTransactionMap[item] {
+			itemUtility += transaction.Utilities[indexOf(transaction.Items, item)]
+		}
+		if itemUtility > 0 {
+			utilities = append(utilities, itemUtility)
+		}
+	}
+
+	if len(utilities) < e.TopK {
+		return minPositiveUtility
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(utilities)))
+	return utilities[e.TopK-1]
+}
+
+// recordHUI ghi nhận một HUI cùng support của nó. Ở chế độ closed, itemset không đóng bị bỏ qua;
+// ở chế độ maximal chỉ giữ các HUI cực đại; ở chế độ top-k chỉ giữ lại k itemset có utility cao nhất.
+// Ở chế độ thường và closed, nếu có sink thì HUI được gửi ngay vào sink thay vì được giữ lại.
+// itemset theo chỉ số item của tìm kiếm được đổi lại sang mã item gốc tại đây.
+func (s *SearchAlgorithms) recordHUI(itemset []int, utility float64, occ occurrences, projection models.Projection) {
+	itemset = s.remap.originalItems(itemset)
+	if s.Closed && !isClosed(itemset, s.originalTransactions(projection)) {
+		s.Logger.Logf(logger.Node, "%v is not closed, skipped.\n", itemset)
+		return
+	}
+	hui := models.NewHighUtilityItemset(itemset, utility)
+	hui.Support = occ.support
+	hui.MinUtility = occ.minUtility
+	hui.MaxUtility = occ.maxUtility
+	hui.Names = s.ItemNames
+	if s.MaxTIDs != 0 {
+		hui.TIDs, hui.TIDsTruncated = collectTIDs(projection, s.MaxTIDs)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Maximal {
+		s.recordMaximal(hui)
+		return
+	}
+	if s.TopK <= 0 && s.sink != nil {
+		s.emit(hui)
+		return
+	}
+	if s.TopK <= 0 {
+		s.HighUtilityItemsets = append(s.HighUtilityItemsets, hui)
+		return
+	}
+
+	heap.Push(&s.topK, hui)
+	if s.topK.Len() > s.TopK {
+		heap.Pop(&s.topK)
+	}
+}
+
+// raiseThreshold trả về ngưỡng hiện hành. Ở chế độ top-k, khi đã có đủ k itemset,
+// ngưỡng được nâng lên utility nhỏ nhất trong số đó; ngưỡng không bao giờ giảm.
+func (s *SearchAlgorithms) raiseThreshold(minU float64) float64 {
+	if s.TopK <= 0 {
+		return minU
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.TopK > 0 && s.topK.Len() == s.TopK && s.topK[0].Utility > minU {
+		return s.topK[0].Utility
+	}
+	return minU
+}
+
+// finishTopK chuyển k itemset trong heap vào HighUtilityItemsets theo utility giảm dần
+// và trả về ngưỡng cuối cùng (utility của itemset thứ k).
+func (s *SearchAlgorithms) finishTopK(minU float64) float64 {
+	minU = s.raiseThreshold(minU)
+	s.HighUtilityItemsets = make([]*models.HighUtilityItemset, len(s.topK))
+	copy(s.HighUtilityItemsets, s.topK)
+	sort.SliceStable(s.HighUtilityItemsets, func(i, j int) bool {
+		return s.HighUtilityItemsets[i].Utility > s.HighUtilityItemsets[j].Utility
+	})
+	s.topK = nil
+	return minU
+}
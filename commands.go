@@ -0,0 +1,658 @@
+package main
+
+import (
+	"EMHUNer/algorithms"
+	"EMHUNer/dataset"
+	"EMHUNer/formats"
+	"EMHUNer/logger"
+	"EMHUNer/models"
+	"EMHUNer/profiler"
+	"EMHUNer/utility"
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseFlags phân tích cờ của một lệnh con, trả về lỗi tham số nếu có
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return badParameters("%v", err)
+	}
+	if fs.NArg() > 0 {
+		return badParameters("unexpected arguments: %v", fs.Args())
+	}
+	return nil
+}
+
+// inputFlags là các cờ chung của các lệnh đọc dataset
+type inputFlags struct {
+	lenient    *bool
+	merge      *bool
+	bufferSize *int
+	profits    *string
+	format     *string
+	columns    *string
+	dictionary *string
+	table      dataset.ProfitTable // bảng lợi nhuận đã đọc từ -profits
+	dict       *dataset.Dictionary // từ điển mã item đã đọc từ -dict
+}
+
+func addInputFlags(fs *flag.FlagSet) *inputFlags {
+	return &inputFlags{
+		lenient:    fs.Bool("lenient", false, "skip invalid lines (reported on stderr) instead of failing at the first one"),
+		merge:      fs.Bool("merge-duplicates", false, "merge an item repeated within a transaction into one item with the summed utility instead of treating the line as invalid"),
+		bufferSize: fs.Int("buffer", dataset.DefaultBufferSize, "read buffer size in bytes (lines longer than the buffer are still read whole)"),
+		profits:    fs.String("profits", "", "unit profit table (\"item profit\" per line); the dataset is then read as items:quantities"),
+		format:     fs.String("input-format", "colon", "dataset format: colon (items:TU:utilities), csv or jsonl (long format, one item of a transaction per row)"),
+		columns:    fs.String("columns", "transaction_id,item,utility", "csv columns or jsonl fields holding the transaction ID, item code and utility"),
+		dictionary: fs.String("dict", "", "item dictionary (ID<TAB>code per line) mapping csv/jsonl item codes to IDs; created or extended with new codes"),
+	}
+}
+
+// options trả về tùy chọn đọc dataset, đọc bảng lợi nhuận -profits ở lần gọi đầu tiên
+func (f *inputFlags) options() (dataset.Options, error) {
+	options := dataset.Options{Mode: dataset.Strict, BufferSize: *f.bufferSize, MergeDuplicates: *f.merge}
+	if *f.lenient {
+		options.Mode = dataset.Lenient
+	}
+	format, err := dataset.ParseFormat(*f.format)
+	if err != nil {
+		return options, badParameters("%v", err)
+	}
+	options.Format = format
+	if format == dataset.FormatColon && *f.dictionary != "" {
+		return options, badParameters("-dict requires -input-format csv or jsonl")
+	}
+	if format != dataset.FormatColon {
+		if *f.profits != "" {
+			return options, badParameters("-profits requires -input-format colon")
+		}
+		if options.Columns, err = dataset.ParseColumns(*f.columns); err != nil {
+			return options, badParameters("%v", err)
+		}
+		if f.dict == nil {
+			f.dict = dataset.NewDictionary()
+			if *f.dictionary != "" {
+				if f.dict, err = dataset.LoadDictionary(*f.dictionary); err != nil {
+					return options, badInput(fmt.Errorf("reading %s: %w", *f.dictionary, err))
+				}
+			}
+		}
+		options.Dictionary = f.dict
+	}
+	if *f.profits != "" && f.table == nil {
+		table, err := dataset.LoadProfitTable(*f.profits)
+		if err != nil {
+			return options, badInput(fmt.Errorf("reading %s: %w", *f.profits, err))
+		}
+		f.table = table
+	}
+	options.Profits = f.table
+	return options, nil
+}
+
+// loadDataset đọc và kiểm tra dataset, chuyển mọi lỗi thành lỗi dữ liệu đầu vào. Ở chế độ lenient,
+// số dòng bị bỏ qua được báo trên stderr. Mã item mới được lưu vào từ điển -dict.
+func loadDataset(fileName string, input *inputFlags) ([]*models.Transaction, error) {
+	options, err := input.options()
+	if err != nil {
+		return nil, err
+	}
+	transactions, report, err := loadDatasetQuietly(fileName, options)
+	if err != nil {
+		return nil, err
+	}
+	if *input.dictionary != "" && input.dict.Modified() {
+		if err := input.dict.Save(*input.dictionary); err != nil {
+			return nil, outputFailure(fmt.Errorf("writing %s: %w", *input.dictionary, err))
+		}
+	}
+	if len(report.Errors) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: skipped %d invalid line(s) of %s, first at %v; run 'emhun validate -input %s' for the full list\n",
+			len(report.Errors), fileName, report.Errors[0], fileName)
+	}
+	if report.Merged > 0 {
+		fmt.Fprintf(os.Stderr, "note: merged repeated items in %d transaction(s) of %s\n", report.Merged, fileName)
+	}
+	return transactions, nil
+}
+
+// loadDatasetQuietly giống loadDataset nhưng trả về báo cáo thay vì in cảnh báo
+func loadDatasetQuietly(fileName string, options dataset.Options) ([]*models.Transaction, *dataset.Report, error) {
+	if fileName == "" {
+		return nil, nil, badParameters("-input is required")
+	}
+	transactions, report, err := dataset.Load(fileName, options)
+	if err != nil {
+		var lineErr *dataset.LineError
+		if errors.As(err, &lineErr) {
+			return nil, nil, badInput(fmt.Errorf("%w (use -lenient to skip invalid lines)", err))
+		}
+		return nil, nil, badInput(fmt.Errorf("reading %s: %w", fileName, err))
+	}
+	if len(transactions) == 0 {
+		return nil, nil, badInput(fmt.Errorf("%s contains no valid transactions", fileName))
+	}
+	return transactions, report, nil
+}
+
+func runMine(args []string) error {
+	fs := flag.NewFlagSet("mine", flag.ContinueOnError)
+	input := fs.String("input", "", "dataset file (see -input-format), optionally gzip or bzip2 compressed, - for stdin (required)")
+	minUtil := fs.Float64("minutil", 0, "absolute minimum utility threshold")
+	ratio := fs.Float64("ratio", 0, "minimum utility as a fraction in (0, 1] of the total positive utility")
+	topK := fs.Int("topk", 0, "mine the k itemsets with the highest utility instead of using a threshold")
+	closed := fs.Bool("closed", false, "return only closed HUIs (no proper superset with the same support)")
+	maximal := fs.Bool("maximal", false, "return only maximal HUIs (no high-utility proper superset)")
+	verify := fs.Bool("verify", false, "with -maximal, check the result against a normal run")
+	appendFile := fs.String("append", "", "transactions appended to the dataset after the initial run, mined incrementally")
+	output := fs.String("output", "", "results file (default output/<dataset>_<minutil>.txt)")
+	format := fs.String("format", "text", "output format: "+strings.Join(formats.Names, ", "))
+	workers := fs.Int("workers", 1, "number of goroutines searching in parallel, 0 for one per CPU")
+	splitDepth := fs.Int("split-depth", 1, "number of search tree levels split into parallel tasks")
+	sortOrder := fs.String("sort", "utility", "order of the results: utility (descending), length or lex")
+	namesFile := fs.String("names", "", "item names (ID<TAB>name[<TAB>category] per line) shown in the results instead of IDs; defaults to the -dict codes for csv/jsonl input")
+	maxTIDs := fs.Int("tids", 0, "record the IDs of up to this many supporting transactions per HUI, -1 for all (json and csv output)")
+	sinkMode := fs.String("sink", "collect", "result delivery: collect (keep all, then sort and write), file (write each HUI to the results file as it is found) or count (only count HUIs)")
+	timeout := fs.Duration("timeout", 0, "stop the search after this long and write the partial results, 0 for no limit")
+	verbosity := fs.Int("v", 1, "verbosity: 0 quiet, 1 summary of each phase, 2 trace every search node and transaction")
+	inputOptions := addInputFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	modes := 0
+	for _, set := range []bool{*minUtil > 0, *ratio > 0, *topK > 0} {
+		if set {
+			modes++
+		}
+	}
+	if modes != 1 {
+		return badParameters("exactly one of -minutil, -ratio or -topk must be a positive number")
+	}
+	if *minUtil < 0 || *ratio < 0 || *ratio > 1 || *topK < 0 {
+		return badParameters("-minutil and -topk must be positive and -ratio must be in (0, 1]")
+	}
+	if *maximal && (*topK > 0 || *closed) {
+		return badParameters("-maximal cannot be combined with -topk or -closed")
+	}
+	if *verify && !*maximal {
+		return badParameters("-verify requires -maximal")
+	}
+	if *appendFile != "" && *topK > 0 {
+		return badParameters("-append cannot be combined with -topk")
+	}
+	if *verify && (*input == dataset.Stdin || *appendFile == dataset.Stdin) {
+		return badParameters("-verify rereads the dataset and cannot be used with stdin input")
+	}
+	if *input == dataset.Stdin && *appendFile == dataset.Stdin {
+		return badParameters("-input and -append cannot both read stdin")
+	}
+	if _, err := formats.New(*format, io.Discard, false); err != nil {
+		return badParameters("%v", err)
+	}
+	switch *sinkMode {
+	case "collect", "file", "count":
+	default:
+		return badParameters("unknown -sink %q", *sinkMode)
+	}
+	if *sinkMode != "collect" && (*appendFile != "" || *verify) {
+		return badParameters("-append and -verify require -sink collect")
+	}
+	if *timeout < 0 {
+		return badParameters("-timeout must not be negative")
+	}
+	if *workers < 0 || *splitDepth < 1 {
+		return badParameters("-workers must not be negative and -split-depth must be at least 1")
+	}
+	order, err := models.ParseSortOrder(*sortOrder)
+	if err != nil {
+		return badParameters("%v", err)
+	}
+
+	// Đo thời gian bắt đầu
+	startTime := time.Now()
+
+	// Lấy mẫu bộ nhớ trong suốt quá trình đọc dữ liệu và chạy thuật toán
+	memory := profiler.NewMemoryProfiler(profiler.DefaultInterval)
+	memory.Start("load")
+	defer memory.Stop()
+
+	transactions, err := loadDataset(*input, inputOptions)
+	if err != nil {
+		return err
+	}
+	if *verbosity >= 2 {
+		fmt.Println("Transactions vừa đọc được:")
+		for i, transaction := range transactions {
+			fmt.Printf("Transaction %d: %s\n", i+1, transaction)
+		}
+	}
+
+	emhun := algorithms.NewEMHUN(transactions, *minUtil)
+	switch {
+	case *ratio > 0:
+		emhun = algorithms.NewEMHUNWithRatio(transactions, *ratio)
+	case *topK > 0:
+		emhun = algorithms.NewEMHUNTopK(transactions, *topK)
+	}
+	emhun.Closed = *closed
+	emhun.Maximal = *maximal
+	emhun.SortOrder = order
+	emhun.Workers = *workers
+	if *workers == 0 {
+		emhun.Workers = -1
+	}
+	emhun.SplitDepth = *splitDepth
+	emhun.MaxTIDs = *maxTIDs
+	switch {
+	case *namesFile != "":
+		if emhun.ItemNames, err = dataset.LoadItemNames(*namesFile); err != nil {
+			return badInput(fmt.Errorf("reading %s: %w", *namesFile, err))
+		}
+	case inputOptions.dict != nil:
+		emhun.ItemNames = inputOptions.dict.Names()
+	}
+	threshold := emhun.ResolveMinUtility()
+	if *verbosity >= 1 {
+		if *topK > 0 {
+			fmt.Printf("Mining %s (%d transactions) for the top-%d itemsets\n", *input, len(transactions), *topK)
+		} else {
+			fmt.Printf("Mining %s (%d transactions) with minUtility = %.2f\n", *input, len(transactions), threshold)
+		}
+	}
+
+	outputFileName := *output
+	if outputFileName == "" {
+		outputFileName = defaultOutputPath(*input, threshold, *topK, formats.Extension(*format))
+	}
+
+	// Với -sink file, các HUI được ghi vào file kết quả ngay khi tìm thấy; phần thông tin được ghi sau
+	var streamWriter formats.Writer
+	var streamFile *os.File
+	switch *sinkMode {
+	case "file":
+		file, err := os.Create(outputFileName)
+		if err != nil {
+			return outputFailure(fmt.Errorf("writing %s: %w", outputFileName, err))
+		}
+		// Đóng lại ở đây chỉ có tác dụng khi dừng sớm; lỗi Close được kiểm tra sau khi ghi xong
+		defer file.Close()
+		streamFile = file
+		streamWriter, err = formats.New(*format, file, emhun.Closed)
+		if err != nil {
+			return badParameters("%v", err)
+		}
+		emhun.Sink = streamWriter
+	case "count":
+		emhun.Sink = algorithms.NewCounterSink()
+	}
+
+	// Ctrl-C hoặc hết -timeout dừng tìm kiếm, kết quả một phần vẫn được ghi ra file
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	emhun.SetLogger(logger.New(os.Stdout, verbosityLevel(*verbosity)))
+	emhun.PhaseObserver = memory
+	result, err := runEMHUN(ctx, emhun)
+	if err != nil {
+		return miningFailure(err)
+	}
+	if result.Err != nil {
+		return outputFailure(fmt.Errorf("writing %s: %w", outputFileName, result.Err))
+	}
+
+	if *appendFile != "" && !result.Incomplete {
+		memory.StartPhase("append")
+		batch, err := loadDataset(*appendFile, inputOptions)
+		if err != nil {
+			return err
+		}
+		offsetTIDs(batch, len(transactions))
+		if *namesFile == "" && inputOptions.dict != nil {
+			// Lô -append có thể thêm mã item mới vào từ điển
+			emhun.ItemNames = inputOptions.dict.Names()
+		}
+		delta, err := emhun.AppendTransactions(batch)
+		if err != nil {
+			return miningFailure(err)
+		}
+		result = delta.Result
+		if *verbosity >= 1 {
+			fmt.Printf("Appended %d transactions from %s: %d HUIs added, %d removed, %d updated\n",
+				len(batch), *appendFile, len(delta.Added), len(delta.Removed), len(delta.Updated))
+		}
+	}
+
+	elapsedTime := time.Since(startTime).Seconds()
+
+	memoryReport := memory.Stop()
+	if *verbosity >= 1 {
+		fmt.Printf("\nThời gian chạy thuật toán: %.6f s\n", elapsedTime)
+		fmt.Printf("Bộ nhớ sử dụng (đỉnh heap): %d KB, đỉnh RSS: %d KB\n", memoryReport.PeakHeap/1024, memoryReport.PeakRSS/1024)
+	}
+
+	if *verify && !result.Incomplete {
+		options, err := inputOptions.options()
+		if err != nil {
+			return err
+		}
+		count, err := verifyMaximal(*input, *appendFile, options, emhun.MinUtility, result)
+		if err != nil {
+			return miningFailure(err)
+		}
+		if *verbosity >= 1 {
+			fmt.Printf("Maximality verified against %d HUIs of a normal run\n", count)
+		}
+	}
+
+	meta := &formats.Metadata{
+		Dataset:           *input,
+		MinUtility:        emhun.MinUtility,
+		MinUtilityRatio:   emhun.MinUtilityRatio,
+		TotalUtility:      emhun.TotalUtility,
+		TopK:              emhun.TopK,
+		Closed:            emhun.Closed,
+		Maximal:           emhun.Maximal,
+		Streamed:          emhun.Sink != nil,
+		Incomplete:        result.Incomplete,
+		CompletedBranches: result.CompletedBranches,
+		ElapsedSeconds:    elapsedTime,
+		Memory:            memoryReport,
+		Stats:             result.Stats,
+	}
+	if streamWriter != nil {
+		err = streamWriter.Close(meta)
+		if cerr := streamFile.Close(); err == nil {
+			err = cerr
+		}
+	} else {
+		err = writeResultsToFile(*format, result, outputFileName, meta)
+	}
+	if err != nil {
+		return outputFailure(fmt.Errorf("writing %s: %w", outputFileName, err))
+	}
+
+	if result.Incomplete {
+		return incompleteRun(fmt.Errorf("mining stopped early after %d of %d top-level branches; partial results (%d HUIs) written to %s",
+			len(result.CompletedBranches), len(emhun.PrimaryItems), result.Stats.HighUtilityItemsets, outputFileName))
+	}
+	if *verbosity >= 1 {
+		fmt.Printf("Found %d HUIs. Results written to %s\n", result.Stats.HighUtilityItemsets, outputFileName)
+	}
+	return nil
+}
+
+// verifyMaximal khai thác lại dataset (kèm lô -append nếu có) ở chế độ thường với cùng ngưỡng
+// và kiểm tra kết quả maximal với toàn bộ HUI, trả về số HUI của lần chạy thường
+func verifyMaximal(input, appendFile string, options dataset.Options, minUtility float64, result *algorithms.Result) (int, error) {
+	transactions, _, err := loadDatasetQuietly(input, options)
+	if err != nil {
+		return 0, err
+	}
+	if appendFile != "" {
+		batch, _, err := loadDatasetQuietly(appendFile, options)
+		if err != nil {
+			return 0, err
+		}
+		offsetTIDs(batch, len(transactions))
+		transactions = append(transactions, batch...)
+	}
+
+	normal, err := runEMHUN(context.Background(), algorithms.NewEMHUN(transactions, minUtility))
+	if err != nil {
+		return 0, err
+	}
+	if err := algorithms.VerifyMaximal(result.HighUtilityItemsets.Items(), normal.HighUtilityItemsets.Items()); err != nil {
+		return 0, err
+	}
+	return normal.HighUtilityItemsets.Len(), nil
+}
+
+// offsetTIDs đánh số tiếp các giao dịch của lô -append sau offset giao dịch của dataset
+func offsetTIDs(batch []*models.Transaction, offset int) {
+	for _, transaction := range batch {
+		transaction.TID += offset
+	}
+}
+
+// runEMHUN chạy thuật toán và chuyển panic (ví dụ dữ liệu không nhất quán) thành lỗi
+func runEMHUN(ctx context.Context, emhun *algorithms.EMHUN) (result *algorithms.Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("EMHUN aborted: %v", r)
+		}
+	}()
+	return emhun.RunContext(ctx), nil
+}
+
+// verbosityLevel ánh xạ cờ -v sang mức log của thuật toán
+func verbosityLevel(verbosity int) logger.Level {
+	switch {
+	case verbosity <= 0:
+		return logger.None
+	case verbosity == 1:
+		return logger.Summary
+	}
+	return logger.Node
+}
+
+// defaultOutputPath dựng tên file kết quả theo quy ước output/<dataset>_<minutil><ext>
+// (hoặc output/<dataset>_top<k><ext> ở chế độ top-k), ext là phần mở rộng của định dạng
+func defaultOutputPath(input string, minUtility float64, topK int, ext string) string {
+	base := "stdin"
+	if input != dataset.Stdin {
+		base = filepath.Base(input)
+		for _, compressed := range []string{".gz", ".bz2"} {
+			base = strings.TrimSuffix(base, compressed)
+		}
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	if topK > 0 {
+		return filepath.Join("output", fmt.Sprintf("%s_top%d%s", base, topK, ext))
+	}
+	return filepath.Join("output", fmt.Sprintf("%s_%.0f%s", base, minUtility, ext))
+}
+
+func runStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	input := fs.String("input", "", "dataset file (see -input-format), optionally gzip or bzip2 compressed, - for stdin (required)")
+	inputOptions := addInputFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	transactions, err := loadDataset(*input, inputOptions)
+	if err != nil {
+		return err
+	}
+
+	hasPositive := make(map[int]bool)
+	hasNegative := make(map[int]bool)
+	items := make(map[int]bool)
+	totalLength, maxLength, mismatches := 0, 0, 0
+	positiveUtility, negativeUtility := 0.0, 0.0
+
+	for _, transaction := range transactions {
+		totalLength += len(transaction.Items)
+		if len(transaction.Items) > maxLength {
+			maxLength = len(transaction.Items)
+		}
+		for j, item := range transaction.Items {
+			items[item] = true
+			u := transaction.Utilities[j]
+			if u > 0 {
+				hasPositive[item] = true
+				positiveUtility += u
+			} else if u < 0 {
+				hasNegative[item] = true
+				negativeUtility += u
+			}
+		}
+		if utility.CalculateTransactionUtility(transaction) != transaction.TransactionUtility {
+			mismatches++
+		}
+	}
+
+	rho, delta, eta := 0, 0, 0
+	for item := range items {
+		switch {
+		case hasPositive[item] && hasNegative[item]:
+			delta++
+		case hasPositive[item]:
+			rho++
+		case hasNegative[item]:
+			eta++
+		}
+	}
+
+	printStat := func(label string, value interface{}) {
+		fmt.Printf("%-28s %v\n", label+":", value)
+	}
+	printStat("Dataset", *input)
+	printStat("Transactions", len(transactions))
+	printStat("Distinct items", len(items))
+	printStat("Average transaction length", fmt.Sprintf("%.2f", float64(totalLength)/float64(len(transactions))))
+	printStat("Max transaction length", maxLength)
+	printStat("Total positive utility", fmt.Sprintf("%.2f", positiveUtility))
+	printStat("Total negative utility", fmt.Sprintf("%.2f", negativeUtility))
+	printStat("Items in ρ (positive only)", rho)
+	printStat("Items in δ (mixed)", delta)
+	printStat("Items in η (negative only)", eta)
+	printStat("TU mismatches", mismatches)
+	return nil
+}
+
+// runValidate kiểm tra mọi dòng của dataset ở chế độ lenient và in các dòng không hợp lệ
+// (cờ -lenient không có tác dụng ở lệnh này)
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	input := fs.String("input", "", "dataset file (see -input-format), optionally gzip or bzip2 compressed, - for stdin (required)")
+	maxErrors := fs.Int("max-errors", 20, "print at most this many invalid lines, 0 for all")
+	inputOptions := addInputFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *input == "" {
+		return badParameters("-input is required")
+	}
+	if *maxErrors < 0 {
+		return badParameters("-max-errors must not be negative")
+	}
+
+	options, err := inputOptions.options()
+	if err != nil {
+		return err
+	}
+	options.Mode = dataset.Lenient
+	_, report, err := dataset.Load(*input, options)
+	if err != nil {
+		return badInput(fmt.Errorf("reading %s: %w", *input, err))
+	}
+	for i, lineErr := range report.Errors {
+		if *maxErrors > 0 && i == *maxErrors {
+			fmt.Printf("... %d more\n", len(report.Errors)-i)
+			break
+		}
+		fmt.Printf("%v [%s]\n", lineErr, lineErr.Kind)
+	}
+
+	fmt.Printf("%s: %d lines, %d valid transactions, %d invalid lines\n",
+		*input, report.Lines, report.Transactions, len(report.Errors))
+	if report.Merged > 0 {
+		fmt.Printf("  %-16s %d\n", "merged:", report.Merged)
+	}
+	counts := report.Counts()
+	for _, kind := range dataset.Kinds {
+		if counts[kind] > 0 {
+			fmt.Printf("  %-16s %d\n", kind+":", counts[kind])
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		return badInput(fmt.Errorf("%s has %d invalid line(s)", *input, len(report.Errors)))
+	}
+	if report.Transactions == 0 {
+		return badInput(fmt.Errorf("%s contains no transactions", *input))
+	}
+	return nil
+}
+
+func runConvert(args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	input := fs.String("input", "", "dataset file (see -input-format), optionally gzip or bzip2 compressed, - for stdin (required)")
+	output := fs.String("output", "", "converted dataset file (required)")
+	recomputeTU := fs.Bool("recompute-tu", false, "replace the declared transaction utility by the sum of item utilities")
+	inputOptions := addInputFlags(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *output == "" {
+		return badParameters("-output is required")
+	}
+
+	transactions, err := loadDataset(*input, inputOptions)
+	if err != nil {
+		return err
+	}
+	if *recomputeTU {
+		for _, transaction := range transactions {
+			transaction.TransactionUtility = utility.CalculateTransactionUtility(transaction)
+		}
+	}
+
+	if err := writeTransactionsToFile(transactions, *output); err != nil {
+		return outputFailure(fmt.Errorf("writing %s: %w", *output, err))
+	}
+	return nil
+}
+
+// writeTransactionsToFile ghi các giao dịch theo định dạng items:TU:utilities của data/*.txt
+func writeTransactionsToFile(transactions []*models.Transaction, fileName string) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	for _, transaction := range transactions {
+		if _, err := writer.WriteString(formatTransaction(transaction) + "\n"); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func formatTransaction(transaction *models.Transaction) string {
+	items := make([]string, len(transaction.Items))
+	for i, item := range transaction.Items {
+		items[i] = strconv.Itoa(item)
+	}
+	utilities := make([]string, len(transaction.Utilities))
+	for i, u := range transaction.Utilities {
+		utilities[i] = formatNumber(u)
+	}
+	return strings.Join(items, " ") + ":" + formatNumber(transaction.TransactionUtility) + ":" + strings.Join(utilities, " ")
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
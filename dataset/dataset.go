@@ -0,0 +1,156 @@
+package dataset
+
+import (
+	"EMHUNer/models"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Mode chọn cách xử lý các dòng không hợp lệ khi đọc dataset
+type Mode int
+
+const (
+	Strict  Mode = iota // dừng ở dòng không hợp lệ đầu tiên, trả về *LineError
+	Lenient             // bỏ qua dòng không hợp lệ và ghi lại trong Report
+)
+
+// ParseMode đọc tên chế độ "strict" hoặc "lenient"
+func ParseMode(name string) (Mode, error) {
+	switch name {
+	case "strict":
+		return Strict, nil
+	case "lenient":
+		return Lenient, nil
+	}
+	return Strict, fmt.Errorf("unknown input mode %q (want strict or lenient)", name)
+}
+
+func (m Mode) String() string {
+	if m == Lenient {
+		return "lenient"
+	}
+	return "strict"
+}
+
+// Options điều chỉnh cách đọc dataset
+type Options struct {
+	Mode       Mode
+	BufferSize int // kích thước bộ đệm đọc, DefaultBufferSize nếu <= 0
+	// Profits khác nil thì dữ liệu ở định dạng định lượng items:quantities (xem ParseQuantityLine)
+	// và utility được tính từ bảng lợi nhuận đơn vị này
+	Profits ProfitTable
+	Format  Format
+	// Columns là tên cột của FormatCSV và FormatJSONL, DefaultColumns nếu bỏ trống
+	Columns Columns
+	// Dictionary cấp ID cho mã item của FormatCSV và FormatJSONL; nil thì dùng một từ điển mới
+	Dictionary *Dictionary
+	// MergeDuplicates gộp các item lặp trong một giao dịch thành một item với utility là tổng các
+	// utility thay vì coi giao dịch là không hợp lệ (KindDuplicateItem)
+	MergeDuplicates bool
+}
+
+func (o Options) columns() Columns {
+	if o.Columns == (Columns{}) {
+		return DefaultColumns
+	}
+	return o.Columns
+}
+
+// Report tóm tắt một lần đọc dataset
+type Report struct {
+	File         string
+	Lines        int // số dòng đã đọc, kể cả dòng trống và chú thích
+	Transactions int
+	Errors       []*LineError // các dòng bị bỏ qua (chế độ Lenient) hoặc dòng gây dừng (Strict)
+	Merged       int          // số giao dịch có item lặp đã được gộp (Options.MergeDuplicates)
+}
+
+// Counts trả về số dòng lỗi theo từng loại
+func (r *Report) Counts() map[Kind]int {
+	counts := make(map[Kind]int)
+	for _, lineErr := range r.Errors {
+		counts[lineErr.Kind]++
+	}
+	return counts
+}
+
+// Load đọc và kiểm tra dataset fileName (Stdin cho đầu vào chuẩn, có thể nén gzip hoặc bzip2)
+func Load(fileName string, options Options) ([]*models.Transaction, *Report, error) {
+	file, err := Open(fileName, options.BufferSize)
+	if err != nil {
+		return nil, &Report{File: fileName}, err
+	}
+	defer file.Close()
+	return Read(file, fileName, options)
+}
+
+// Read đọc và kiểm tra các giao dịch từ r; name là tên dùng trong thông báo lỗi. Dòng trống và dòng
+// chú thích (bắt đầu bằng #, % hoặc @) bị bỏ qua. Mỗi giao dịch hợp lệ nhận TID là số thứ tự của nó
+// trong các giao dịch hợp lệ, bắt đầu từ 1. Độ dài dòng không bị giới hạn.
+//
+// Với FormatCSV và FormatJSONL, các dòng cùng mã giao dịch được ghép thành một giao dịch có TU là
+// tổng utility; ở chế độ Lenient, giao dịch có một dòng không hợp lệ bị bỏ toàn bộ.
+func Read(r io.Reader, name string, options Options) ([]*models.Transaction, *Report, error) {
+	switch options.Format {
+	case FormatCSV:
+		return readCSV(r, name, options)
+	case FormatJSONL:
+		return readJSONL(r, name, options)
+	}
+
+	report := &Report{File: name}
+	var transactions []*models.Transaction
+
+	parse := func(line string) (*models.Transaction, error) {
+		return parseLine(line, options.MergeDuplicates)
+	}
+	if options.Profits != nil {
+		parse = func(line string) (*models.Transaction, error) {
+			return parseQuantityLine(line, options.Profits, options.MergeDuplicates)
+		}
+	}
+
+	lines := NewLineReader(r, options.BufferSize)
+	for {
+		line, err := lines.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, report, fmt.Errorf("%s:%d: %w", name, report.Lines+1, err)
+		}
+		report.Lines++
+		if isBlankOrComment(line) {
+			continue
+		}
+
+		transaction, err := parse(line)
+		if err != nil {
+			lineErr := &LineError{File: name, Line: report.Lines, Reason: err.Error()}
+			var parseErr *ParseError
+			if errors.As(err, &parseErr) {
+				lineErr.Kind = parseErr.Kind
+			}
+			report.Errors = append(report.Errors, lineErr)
+			if options.Mode == Strict {
+				return nil, report, lineErr
+			}
+			continue
+		}
+		if options.MergeDuplicates && mergeDuplicateItems(transaction) {
+			report.Merged++
+		}
+		transaction.TID = len(transactions) + 1
+		transactions = append(transactions, transaction)
+	}
+
+	report.Transactions = len(transactions)
+	return transactions, report, nil
+}
+
+func isBlankOrComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "%") || strings.HasPrefix(trimmed, "@")
+}
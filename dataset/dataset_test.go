@@ -0,0 +1,100 @@
+package dataset
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line string
+		kind Kind // rỗng nếu dòng hợp lệ
+	}{
+		{"1 2 4:6:-4 2 8", ""},
+		{"1 2 4:10:-4 2 8", ""}, // TU là tổng utility dương
+		{"3 5:2.5:1 1.5", ""},
+		{"1 2 4:6", KindFormat},
+		{"", KindFormat},
+		{"1 x:3:1 2", KindNumber},
+		{"1 2:abc:1 2", KindNumber},
+		{"1 2:3:1 NaN", KindNumber},
+		{" :0:", KindEmpty},
+		{"1 2 3:3:1 2", KindCountMismatch},
+		{"1 2 1:4:1 2 1", KindDuplicateItem},
+		{"1 2:7:1 2", KindTUMismatch},
+	}
+	for _, test := range tests {
+		transaction, err := ParseLine(test.line)
+		if test.kind == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %v", test.line, err)
+			} else if len(transaction.Items) != len(transaction.Utilities) {
+				t.Errorf("%q: parsed %+v", test.line, transaction)
+			}
+			continue
+		}
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || parseErr.Kind != test.kind {
+			t.Errorf("%q: got error %v, want kind %q", test.line, err, test.kind)
+		}
+	}
+}
+
+func TestReadModes(t *testing.T) {
+	input := strings.Join([]string{
+		"# comment",
+		"1 2:3:1 2",
+		"",
+		"1 2 3:3:1 2",
+		"2 3:5:2 3",
+		"4 4:2:1 1",
+	}, "\n")
+
+	_, report, err := Read(strings.NewReader(input), "test.txt", Options{Mode: Strict})
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 4 || lineErr.Kind != KindCountMismatch {
+		t.Fatalf("strict: got error %v, want a count mismatch at line 4", err)
+	}
+	if !strings.HasPrefix(err.Error(), "test.txt:4: ") || len(report.Errors) != 1 {
+		t.Errorf("strict: error %q, report %+v", err, report)
+	}
+
+	transactions, report, err := Read(strings.NewReader(input), "test.txt", Options{Mode: Lenient})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 2 || transactions[0].TID != 1 || transactions[1].TID != 2 || transactions[1].Items[0] != 2 {
+		t.Fatalf("lenient: got %d transactions %v", len(transactions), transactions)
+	}
+	if report.Lines != 6 || report.Transactions != 2 || len(report.Errors) != 2 ||
+		report.Errors[0].Line != 4 || report.Errors[1].Line != 6 || report.Errors[1].Kind != KindDuplicateItem {
+		t.Errorf("lenient: unexpected report %+v", report)
+	}
+	if counts := report.Counts(); counts[KindCountMismatch] != 1 || counts[KindDuplicateItem] != 1 {
+		t.Errorf("lenient: counts %v", counts)
+	}
+}
+
+func TestReadMergeDuplicates(t *testing.T) {
+	input := "4 2 4:6:1 3 2\n1 2:3:1 2\n5 5:-1:-4 3\n"
+	transactions, report, err := Read(strings.NewReader(input), "test.txt", Options{MergeDuplicates: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 3 || report.Merged != 2 || len(report.Errors) != 0 {
+		t.Fatalf("got %d transactions, report %+v", len(transactions), report)
+	}
+	if got := transactions[0]; fmt.Sprint(got.Items, got.Utilities) != "[4 2] [3 3]" || got.TransactionUtility != 6 {
+		t.Errorf("first transaction %v", got)
+	}
+	if got := transactions[2]; fmt.Sprint(got.Items, got.Utilities) != "[5] [-1]" {
+		t.Errorf("third transaction %v", got)
+	}
+
+	// Tổng utility vẫn được kiểm tra trên các utility trước khi gộp
+	if _, _, err := Read(strings.NewReader("4 4:5:1 2\n"), "test.txt", Options{MergeDuplicates: true}); err == nil {
+		t.Error("TU mismatch accepted after merging")
+	}
+}
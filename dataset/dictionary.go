@@ -0,0 +1,149 @@
+package dataset
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dictionary ánh xạ mã item dạng chuỗi sang ID nguyên dùng trong models.Transaction và ngược lại.
+// Được lưu ra file để các lần nhập sau giữ nguyên ID của các mã đã có.
+type Dictionary struct {
+	ids      map[string]int
+	codes    map[int]string
+	next     int  // ID sẽ cấp cho mã mới
+	modified bool // có mã mới kể từ khi đọc hoặc lưu
+}
+
+// NewDictionary tạo từ điển rỗng, cấp ID từ 1
+func NewDictionary() *Dictionary {
+	return &Dictionary{ids: make(map[string]int), codes: make(map[int]string), next: 1}
+}
+
+// LoadDictionary đọc từ điển từ fileName; file không tồn tại cho từ điển rỗng
+func LoadDictionary(fileName string) (*Dictionary, error) {
+	file, err := os.Open(fileName)
+	if os.IsNotExist(err) {
+		return NewDictionary(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadDictionary(file, fileName)
+}
+
+// ReadDictionary đọc từ điển, mỗi dòng "id<TAB>mã"; dòng trống và chú thích bị bỏ qua
+func ReadDictionary(r io.Reader, name string) (*Dictionary, error) {
+	d := NewDictionary()
+	lines := NewLineReader(r, 0)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := lines.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, lineNumber, err)
+		}
+		if isBlankOrComment(line) {
+			continue
+		}
+
+		lineErr := func(kind Kind, format string, args ...interface{}) error {
+			return &LineError{File: name, Line: lineNumber, Kind: kind, Reason: fmt.Sprintf(format, args...)}
+		}
+		idField, code, ok := strings.Cut(line, "\t")
+		if !ok || code == "" {
+			return nil, lineErr(KindFormat, "expected \"id<TAB>code\"")
+		}
+		id, err := strconv.Atoi(idField)
+		if err != nil {
+			return nil, lineErr(KindNumber, "item ID %q is not an integer", idField)
+		}
+		if _, found := d.codes[id]; found {
+			return nil, lineErr(KindDuplicateItem, "item ID %d appears more than once", id)
+		}
+		if _, found := d.ids[code]; found {
+			return nil, lineErr(KindDuplicateItem, "code %q appears more than once", code)
+		}
+		d.ids[code] = id
+		d.codes[id] = code
+		if id >= d.next {
+			d.next = id + 1
+		}
+	}
+	return d, nil
+}
+
+// ID trả về ID của code, cấp ID mới nếu code chưa có trong từ điển
+func (d *Dictionary) ID(code string) int {
+	if id, found := d.ids[code]; found {
+		return id
+	}
+	id := d.next
+	d.next++
+	d.ids[code] = id
+	d.codes[id] = code
+	d.modified = true
+	return id
+}
+
+// Lookup trả về ID của code nếu code đã có trong từ điển
+func (d *Dictionary) Lookup(code string) (int, bool) {
+	id, found := d.ids[code]
+	return id, found
+}
+
+// Code trả về mã của item id
+func (d *Dictionary) Code(id int) (string, bool) {
+	code, found := d.codes[id]
+	return code, found
+}
+
+// Len trả về số mã trong từ điển
+func (d *Dictionary) Len() int {
+	return len(d.ids)
+}
+
+// Modified cho biết từ điển có mã mới chưa được lưu
+func (d *Dictionary) Modified() bool {
+	return d.modified
+}
+
+// Write ghi từ điển theo thứ tự ID tăng dần, định dạng đọc được bằng ReadDictionary
+func (d *Dictionary) Write(w io.Writer) error {
+	ids := make([]int, 0, len(d.codes))
+	for id := range d.codes {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	buffered := bufio.NewWriter(w)
+	for _, id := range ids {
+		if _, err := fmt.Fprintf(buffered, "%d\t%s\n", id, d.codes[id]); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
+
+// Save ghi từ điển ra fileName
+func (d *Dictionary) Save(fileName string) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := d.Write(file); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	d.modified = false
+	return nil
+}
@@ -0,0 +1,68 @@
+package dataset
+
+import (
+	"EMHUNer/models"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// LoadItemNames đọc từ điển tên item từ fileName (có thể nén gzip hoặc bzip2)
+func LoadItemNames(fileName string) (models.ItemNames, error) {
+	file, err := Open(fileName, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadItemNames(file, fileName)
+}
+
+// ReadItemNames đọc từ điển tên item, mỗi dòng "id<TAB>tên" hoặc "id<TAB>tên<TAB>nhóm"; dòng trống
+// và chú thích bị bỏ qua. File từ điển do Dictionary.Save ghi cũng đọc được, với mã item làm tên.
+func ReadItemNames(r io.Reader, name string) (models.ItemNames, error) {
+	names := make(models.ItemNames)
+	lines := NewLineReader(r, 0)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := lines.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, lineNumber, err)
+		}
+		if isBlankOrComment(line) {
+			continue
+		}
+
+		lineErr := func(kind Kind, format string, args ...interface{}) error {
+			return &LineError{File: name, Line: lineNumber, Kind: kind, Reason: fmt.Sprintf(format, args...)}
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 || len(fields) > 3 || fields[1] == "" {
+			return nil, lineErr(KindFormat, "expected \"id<TAB>name\" or \"id<TAB>name<TAB>category\"")
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+		if err != nil {
+			return nil, lineErr(KindNumber, "item ID %q is not an integer", fields[0])
+		}
+		if _, found := names[id]; found {
+			return nil, lineErr(KindDuplicateItem, "item ID %d appears more than once", id)
+		}
+		info := models.ItemInfo{Name: fields[1]}
+		if len(fields) == 3 {
+			info.Category = fields[2]
+		}
+		names[id] = info
+	}
+	return names, nil
+}
+
+// Names trả về từ điển tên item với mã của mỗi item làm tên
+func (d *Dictionary) Names() models.ItemNames {
+	names := make(models.ItemNames, len(d.codes))
+	for id, code := range d.codes {
+		names[id] = models.ItemInfo{Name: code}
+	}
+	return names
+}
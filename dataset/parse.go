@@ -0,0 +1,162 @@
+package dataset
+
+import (
+	"EMHUNer/models"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Kind phân loại lỗi của một dòng dữ liệu
+type Kind string
+
+const (
+	KindFormat        Kind = "format"         // không có đúng ba phần items:TU:utilities
+	KindNumber        Kind = "number"         // item, TU hoặc utility không phải số hợp lệ
+	KindEmpty         Kind = "empty"          // giao dịch không có item nào
+	KindCountMismatch Kind = "count mismatch" // số item khác số utility
+	KindDuplicateItem Kind = "duplicate item" // một item xuất hiện hai lần trong giao dịch
+	KindTUMismatch    Kind = "TU mismatch"    // TU khai báo khác tổng utility
+	KindUnknownItem   Kind = "unknown item"   // item không có trong bảng lợi nhuận đơn vị
+)
+
+// Kinds liệt kê các loại lỗi theo thứ tự được kiểm tra
+var Kinds = []Kind{KindFormat, KindNumber, KindEmpty, KindCountMismatch, KindDuplicateItem, KindTUMismatch, KindUnknownItem}
+
+// LineError là lỗi của một dòng trong file dữ liệu
+type LineError struct {
+	File   string
+	Line   int
+	Kind   Kind
+	Reason string
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Reason)
+}
+
+// ParseError là lỗi của ParseLine, chưa gắn tên file và số dòng
+type ParseError struct {
+	Kind   Kind
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return e.Reason
+}
+
+func newParseError(kind Kind, format string, args ...interface{}) *ParseError {
+	return &ParseError{Kind: kind, Reason: fmt.Sprintf(format, args...)}
+}
+
+// ParseLine đọc và kiểm tra một giao dịch theo định dạng items:TU:utilities. TU khai báo phải bằng
+// tổng utility hoặc tổng utility dương (quy ước của các dataset có utility âm). Lỗi trả về là *ParseError.
+func ParseLine(line string) (*models.Transaction, error) {
+	return parseLine(line, false)
+}
+
+// parseLine là ParseLine; allowDuplicates cho phép item lặp để Read gộp lại sau (Options.MergeDuplicates)
+func parseLine(line string, allowDuplicates bool) (*models.Transaction, error) {
+	parts := strings.Split(line, ":")
+	if len(parts) != 3 {
+		return nil, newParseError(KindFormat, "expected items:TU:utilities, found %d part(s)", len(parts))
+	}
+
+	items, err := parseItems(parts[0], allowDuplicates)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionUtility, err := parseNumber(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return nil, newParseError(KindNumber, "transaction utility %q is not a finite number", strings.TrimSpace(parts[1]))
+	}
+
+	utilityFields := strings.Fields(parts[2])
+	if len(utilityFields) != len(items) {
+		return nil, newParseError(KindCountMismatch, "%d items but %d utilities", len(items), len(utilityFields))
+	}
+	utilities := make([]float64, len(utilityFields))
+	sum, positiveSum := 0.0, 0.0
+	for i, field := range utilityFields {
+		u, err := parseNumber(field)
+		if err != nil {
+			return nil, newParseError(KindNumber, "utility %q is not a finite number", field)
+		}
+		utilities[i] = u
+		sum += u
+		if u > 0 {
+			positiveSum += u
+		}
+	}
+	if !nearlyEqual(transactionUtility, sum) && !nearlyEqual(transactionUtility, positiveSum) {
+		return nil, newParseError(KindTUMismatch, "declared TU %s but utilities sum to %s (positive %s)",
+			formatNumber(transactionUtility), formatNumber(sum), formatNumber(positiveSum))
+	}
+
+	return models.NewTransaction(items, utilities, transactionUtility), nil
+}
+
+// parseItems đọc danh sách item cách nhau bởi khoảng trắng, không rỗng và không lặp (trừ khi allowDuplicates)
+func parseItems(field string, allowDuplicates bool) ([]int, error) {
+	itemFields := strings.Fields(field)
+	if len(itemFields) == 0 {
+		return nil, newParseError(KindEmpty, "transaction has no items")
+	}
+	items := make([]int, len(itemFields))
+	seen := make(map[int]bool, len(itemFields))
+	for i, field := range itemFields {
+		item, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, newParseError(KindNumber, "item %q is not an integer", field)
+		}
+		if seen[item] && !allowDuplicates {
+			return nil, newParseError(KindDuplicateItem, "item %d appears more than once", item)
+		}
+		seen[item] = true
+		items[i] = item
+	}
+	return items, nil
+}
+
+// mergeDuplicateItems gộp các lần xuất hiện lặp của một item trong giao dịch thành lần đầu tiên với utility
+// là tổng các utility của item đó, giữ nguyên thứ tự item và TU. Trả về true nếu giao dịch có item lặp.
+func mergeDuplicateItems(transaction *models.Transaction) bool {
+	position := make(map[int]int, len(transaction.Items))
+	items := transaction.Items[:0]
+	utilities := transaction.Utilities[:0]
+	for i, item := range transaction.Items {
+		if j, found := position[item]; found {
+			utilities[j] += transaction.Utilities[i]
+			continue
+		}
+		position[item] = len(items)
+		items = append(items, item)
+		utilities = append(utilities, transaction.Utilities[i])
+	}
+	merged := len(items) < len(transaction.Items)
+	transaction.Items, transaction.Utilities = items, utilities
+	return merged
+}
+
+// parseNumber đọc một số thực hữu hạn (ParseFloat chấp nhận cả NaN và Inf)
+func parseNumber(field string) (float64, error) {
+	value, err := strconv.ParseFloat(field, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("%q is not finite", field)
+	}
+	return value, nil
+}
+
+// nearlyEqual so sánh hai tổng utility với sai số làm tròn tương đối
+func nearlyEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
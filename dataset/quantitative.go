@@ -0,0 +1,108 @@
+package dataset
+
+import (
+	"EMHUNer/models"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ProfitTable là lợi nhuận đơn vị của từng item; lợi nhuận âm biểu diễn item khuyến mãi
+type ProfitTable map[int]float64
+
+// LoadProfitTable đọc bảng lợi nhuận đơn vị từ fileName (có thể nén gzip hoặc bzip2)
+func LoadProfitTable(fileName string) (ProfitTable, error) {
+	file, err := Open(fileName, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ReadProfitTable(file, fileName)
+}
+
+// ReadProfitTable đọc bảng lợi nhuận đơn vị, mỗi dòng một cặp "item profit" (cách nhau bởi khoảng trắng,
+// dấu phẩy hoặc dấu hai chấm). Dòng trống và chú thích bị bỏ qua; mọi dòng khác phải hợp lệ và mỗi item
+// chỉ được khai báo một lần.
+func ReadProfitTable(r io.Reader, name string) (ProfitTable, error) {
+	profits := make(ProfitTable)
+	lines := NewLineReader(r, 0)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := lines.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, lineNumber, err)
+		}
+		if isBlankOrComment(line) {
+			continue
+		}
+
+		lineErr := func(kind Kind, format string, args ...interface{}) error {
+			return &LineError{File: name, Line: lineNumber, Kind: kind, Reason: fmt.Sprintf(format, args...)}
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ':' || r == ' ' || r == '\t'
+		})
+		if len(fields) != 2 {
+			return nil, lineErr(KindFormat, "expected \"item profit\", found %d field(s)", len(fields))
+		}
+		item, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, lineErr(KindNumber, "item %q is not an integer", fields[0])
+		}
+		profit, err := parseNumber(fields[1])
+		if err != nil {
+			return nil, lineErr(KindNumber, "profit %q is not a finite number", fields[1])
+		}
+		if _, found := profits[item]; found {
+			return nil, lineErr(KindDuplicateItem, "item %d has more than one unit profit", item)
+		}
+		profits[item] = profit
+	}
+	if len(profits) == 0 {
+		return nil, fmt.Errorf("%s contains no unit profits", name)
+	}
+	return profits, nil
+}
+
+// ParseQuantityLine đọc một giao dịch theo định dạng định lượng items:quantities (số lượng dương,
+// có thể là số thực) và tính utility của mỗi item bằng số lượng × lợi nhuận đơn vị. TU của giao dịch
+// là tổng các utility. Lỗi trả về là *ParseError.
+func ParseQuantityLine(line string, profits ProfitTable) (*models.Transaction, error) {
+	return parseQuantityLine(line, profits, false)
+}
+
+// parseQuantityLine là ParseQuantityLine; allowDuplicates cho phép item lặp như parseLine
+func parseQuantityLine(line string, profits ProfitTable, allowDuplicates bool) (*models.Transaction, error) {
+	parts := strings.Split(line, ":")
+	if len(parts) != 2 {
+		return nil, newParseError(KindFormat, "expected items:quantities, found %d part(s)", len(parts))
+	}
+
+	items, err := parseItems(parts[0], allowDuplicates)
+	if err != nil {
+		return nil, err
+	}
+	quantityFields := strings.Fields(parts[1])
+	if len(quantityFields) != len(items) {
+		return nil, newParseError(KindCountMismatch, "%d items but %d quantities", len(items), len(quantityFields))
+	}
+
+	utilities := make([]float64, len(items))
+	transactionUtility := 0.0
+	for i, field := range quantityFields {
+		quantity, err := parseNumber(field)
+		if err != nil || quantity <= 0 {
+			return nil, newParseError(KindNumber, "quantity %q is not a positive number", field)
+		}
+		profit, found := profits[items[i]]
+		if !found {
+			return nil, newParseError(KindUnknownItem, "item %d has no unit profit", items[i])
+		}
+		utilities[i] = quantity * profit
+		transactionUtility += utilities[i]
+	}
+	return models.NewTransaction(items, utilities, transactionUtility), nil
+}
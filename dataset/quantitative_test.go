@@ -0,0 +1,52 @@
+package dataset
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestQuantitativeFormat(t *testing.T) {
+	profits, err := ReadProfitTable(strings.NewReader("# item profit\n1 5\n2,-2\n3:0.5\n"), "profits.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profits) != 3 || profits[2] != -2 || profits[3] != 0.5 {
+		t.Fatalf("got profit table %v", profits)
+	}
+
+	input := "1 2 3:2 3 4\n2:1\n1 4:1 1\n1 2:1\n"
+	transactions, report, err := Read(strings.NewReader(input), "quantities.txt", Options{Mode: Lenient, Profits: profits})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 2 {
+		t.Fatalf("got %d transactions, want 2", len(transactions))
+	}
+	first := transactions[0]
+	if first.Utilities[0] != 10 || first.Utilities[1] != -6 || first.Utilities[2] != 2 || first.TransactionUtility != 6 {
+		t.Errorf("first transaction %v", first)
+	}
+	if transactions[1].TransactionUtility != -2 || transactions[1].TID != 2 {
+		t.Errorf("second transaction %v", transactions[1])
+	}
+	if len(report.Errors) != 2 || report.Errors[0].Kind != KindUnknownItem || report.Errors[1].Kind != KindCountMismatch {
+		t.Errorf("unexpected report %+v", report.Errors)
+	}
+
+	// Dòng đã chuyển đổi phải đọc lại được bằng định dạng items:TU:utilities
+	if _, err := ParseLine("1 2 3:6:10 -6 2"); err != nil {
+		t.Errorf("converted line rejected: %v", err)
+	}
+
+	for _, table := range []string{"1 5\n1 6\n", "1\n", "x 5\n", ""} {
+		_, err := ReadProfitTable(strings.NewReader(table), "profits.txt")
+		var lineErr *LineError
+		if err == nil || (table != "" && !errors.As(err, &lineErr)) {
+			t.Errorf("profit table %q: got error %v", table, err)
+		}
+	}
+	if _, err := ParseQuantityLine("1:0", profits); err == nil {
+		t.Error("zero quantity accepted")
+	}
+}
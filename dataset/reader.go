@@ -0,0 +1,106 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"os"
+)
+
+// DefaultBufferSize là kích thước bộ đệm đọc mặc định. Dòng dài hơn bộ đệm vẫn được đọc trọn vẹn;
+// bộ đệm chỉ quyết định mỗi lần đọc từ file lấy bao nhiêu byte.
+const DefaultBufferSize = 1 << 20
+
+// Stdin là tên file đại diện cho đầu vào chuẩn
+const Stdin = "-"
+
+// LineReader đọc từng dòng từ một io.Reader, không giới hạn độ dài dòng (khác bufio.Scanner).
+// Chỉ dòng đang đọc được giữ trong bộ nhớ, nên file lớn không bị nạp toàn bộ.
+type LineReader struct {
+	r    *bufio.Reader
+	long []byte // nơi ghép các phần của một dòng dài hơn bộ đệm
+}
+
+// NewLineReader tạo LineReader với bộ đệm bufferSize byte (DefaultBufferSize nếu bufferSize <= 0)
+func NewLineReader(r io.Reader, bufferSize int) *LineReader {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &LineReader{r: bufio.NewReaderSize(r, bufferSize)}
+}
+
+// Next trả về dòng tiếp theo, bỏ ký tự xuống dòng \n hoặc \r\n ở cuối; trả về io.EOF khi hết dữ liệu
+func (l *LineReader) Next() (string, error) {
+	l.long = l.long[:0]
+	for {
+		chunk, err := l.r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			l.long = append(l.long, chunk...)
+			continue
+		}
+		line := chunk
+		if len(l.long) > 0 {
+			l.long = append(l.long, chunk...)
+			line = l.long
+		}
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return "", err
+		}
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		line = bytes.TrimSuffix(line, []byte("\r"))
+		return string(line), nil
+	}
+}
+
+// Open mở fileName để đọc, hoặc đầu vào chuẩn nếu fileName là Stdin. File nén gzip hoặc bzip2
+// được nhận diện theo các byte đầu và giải nén trong lúc đọc.
+func Open(fileName string, bufferSize int) (io.ReadCloser, error) {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	var file *os.File
+	if fileName == Stdin {
+		file = os.Stdin
+	} else {
+		var err error
+		if file, err = os.Open(fileName); err != nil {
+			return nil, err
+		}
+	}
+	closeFile := func() error {
+		if file == os.Stdin {
+			return nil
+		}
+		return file.Close()
+	}
+
+	buffered := bufio.NewReaderSize(file, bufferSize)
+	magic, _ := buffered.Peek(3)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		decompressed, err := gzip.NewReader(buffered)
+		if err != nil {
+			closeFile()
+			return nil, err
+		}
+		return &input{Reader: decompressed, close: func() error {
+			decompressed.Close()
+			return closeFile()
+		}}, nil
+	case bytes.Equal(magic, []byte("BZh")):
+		return &input{Reader: bzip2.NewReader(buffered), close: closeFile}, nil
+	}
+	return &input{Reader: buffered, close: closeFile}, nil
+}
+
+// input ghép luồng đã giải nén với hàm đóng file gốc
+type input struct {
+	io.Reader
+	close func() error
+}
+
+func (i *input) Close() error {
+	return i.close()
+}
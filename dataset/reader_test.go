@@ -0,0 +1,73 @@
+package dataset
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLineReaderLongLines(t *testing.T) {
+	// Một giao dịch 20000 item dài hơn nhiều so với giới hạn 64 KB của bufio.Scanner
+	const n = 20000
+	items := make([]string, n)
+	utilities := make([]string, n)
+	for i := range items {
+		items[i] = strconv.Itoa(100000 + i)
+		utilities[i] = "1"
+	}
+	long := strings.Join(items, " ") + ":" + strconv.Itoa(n) + ":" + strings.Join(utilities, " ")
+	input := long + "\r\n1 2:3:1 2\n" + long
+
+	lines := NewLineReader(strings.NewReader(input), 16)
+	for i, want := range []string{long, "1 2:3:1 2", long} {
+		got, err := lines.Next()
+		if err != nil || got != want {
+			t.Fatalf("line %d: got %d bytes (error %v), want %d bytes", i+1, len(got), err, len(want))
+		}
+	}
+	if _, err := lines.Next(); err != io.EOF {
+		t.Fatalf("got %v after the last line, want io.EOF", err)
+	}
+
+	transactions, _, err := Read(strings.NewReader(input), "long.txt", Options{BufferSize: 4096})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 3 || len(transactions[0].Items) != n || len(transactions[2].Utilities) != n {
+		t.Fatalf("got %d transactions", len(transactions))
+	}
+}
+
+func TestLoadCompressed(t *testing.T) {
+	dir := t.TempDir()
+	plain := "1 2:3:1 2\n5:4:4\n"
+
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	writer.Write([]byte(plain))
+	writer.Close()
+
+	// bzip2 của plain (thư viện chuẩn chỉ có bộ giải nén bzip2)
+	bzipped := []byte{0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0xf7, 0x55, 0x9e, 0x0f, 0x00, 0x00,
+		0x02, 0xd8, 0x00, 0x00, 0x10, 0x40, 0x00, 0x3e, 0x10, 0x20, 0x00, 0x21, 0xa3, 0x4c, 0x21, 0x0c, 0x08, 0x69,
+		0x0a, 0x98, 0x38, 0x63, 0x97, 0xc5, 0xdc, 0x91, 0x4e, 0x14, 0x24, 0x3d, 0xd5, 0x67, 0x83, 0xc0}
+
+	for name, content := range map[string][]byte{"plain.txt": []byte(plain), "data.gz": gzipped.Bytes(), "data.bz2": bzipped} {
+		fileName := filepath.Join(dir, name)
+		if err := os.WriteFile(fileName, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		transactions, _, err := Load(fileName, Options{})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(transactions) != 2 || transactions[1].Items[0] != 5 || transactions[1].TransactionUtility != 4 {
+			t.Errorf("%s: got %v", name, transactions)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package dataset
+
+import (
+	"EMHUNer/models"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format là định dạng của file dữ liệu
+type Format string
+
+const (
+	FormatColon Format = "colon" // items:TU:utilities (hoặc items:quantities với Options.Profits)
+	FormatCSV   Format = "csv"   // CSV dạng dài, mỗi dòng một cặp (giao dịch, item), dòng đầu là tiêu đề
+	FormatJSONL Format = "jsonl" // JSON lines dạng dài, mỗi dòng một object
+)
+
+// ParseFormat đọc tên định dạng; chuỗi rỗng là FormatColon
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case "", FormatColon:
+		return FormatColon, nil
+	case FormatCSV, FormatJSONL:
+		return Format(name), nil
+	}
+	return FormatColon, fmt.Errorf("unknown input format %q (want colon, csv or jsonl)", name)
+}
+
+// Columns là tên các cột (CSV) hoặc trường (JSON lines) của dữ liệu dạng dài
+type Columns struct {
+	Transaction string
+	Item        string
+	Utility     string
+}
+
+// DefaultColumns là tên cột mặc định
+var DefaultColumns = Columns{Transaction: "transaction_id", Item: "item", Utility: "utility"}
+
+// ParseColumns đọc danh sách "giao dịch,item,utility"
+func ParseColumns(list string) (Columns, error) {
+	names := strings.Split(list, ",")
+	if len(names) != 3 {
+		return Columns{}, fmt.Errorf("expected three column names transaction,item,utility, got %q", list)
+	}
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+		if names[i] == "" {
+			return Columns{}, fmt.Errorf("empty column name in %q", list)
+		}
+	}
+	return Columns{Transaction: names[0], Item: names[1], Utility: names[2]}, nil
+}
+
+// basket là một giao dịch đang được ghép từ các dòng dạng dài
+type basket struct {
+	items     []int
+	utilities []float64
+	invalid   bool // một dòng của giao dịch không hợp lệ, cả giao dịch bị bỏ (chế độ Lenient)
+	merged    bool // giao dịch có item lặp đã được gộp (Options.MergeDuplicates)
+}
+
+// grouper ghép các dòng dạng dài thành giao dịch theo thứ tự xuất hiện đầu tiên của mã giao dịch
+type grouper struct {
+	name       string
+	mode       Mode
+	merge      bool
+	dictionary *Dictionary
+	report     *Report
+	order      []string
+	baskets    map[string]*basket
+}
+
+func newGrouper(name string, options Options) *grouper {
+	dictionary := options.Dictionary
+	if dictionary == nil {
+		dictionary = NewDictionary()
+	}
+	return &grouper{name: name, mode: options.Mode, merge: options.MergeDuplicates, dictionary: dictionary, report: &Report{File: name}, baskets: make(map[string]*basket)}
+}
+
+// fail ghi lại lỗi của dòng line và đánh dấu giao dịch transactionID (nếu đã biết) là không hợp lệ.
+// Ở chế độ Strict, lỗi được trả về để dừng việc đọc.
+func (g *grouper) fail(line int, transactionID string, kind Kind, format string, args ...interface{}) error {
+	lineErr := &LineError{File: g.name, Line: line, Kind: kind, Reason: fmt.Sprintf(format, args...)}
+	g.report.Errors = append(g.report.Errors, lineErr)
+	if b, found := g.baskets[transactionID]; found {
+		b.invalid = true
+	} else if transactionID != "" {
+		g.order = append(g.order, transactionID)
+		g.baskets[transactionID] = &basket{invalid: true}
+	}
+	if g.mode == Strict {
+		return lineErr
+	}
+	return nil
+}
+
+// add thêm một dòng (giao dịch, mã item, utility) đã đọc ở dòng line
+func (g *grouper) add(line int, transactionID, code, utilityField string) error {
+	if transactionID == "" {
+		return g.fail(line, "", KindFormat, "empty transaction ID")
+	}
+	if code == "" || strings.ContainsAny(code, "\t\r\n") {
+		return g.fail(line, transactionID, KindFormat, "item code %q is empty or contains a tab or newline", code)
+	}
+	utility, err := parseNumber(strings.TrimSpace(utilityField))
+	if err != nil {
+		return g.fail(line, transactionID, KindNumber, "utility %q is not a finite number", utilityField)
+	}
+
+	b, found := g.baskets[transactionID]
+	if !found {
+		b = &basket{}
+		g.order = append(g.order, transactionID)
+		g.baskets[transactionID] = b
+	}
+	if b.invalid {
+		return nil
+	}
+	item := g.dictionary.ID(code)
+	for i, existing := range b.items {
+		if existing == item && g.merge {
+			b.utilities[i] += utility
+			b.merged = true
+			return nil
+		}
+		if existing == item {
+			return g.fail(line, transactionID, KindDuplicateItem, "item %q appears more than once in transaction %q", code, transactionID)
+		}
+	}
+	b.items = append(b.items, item)
+	b.utilities = append(b.utilities, utility)
+	return nil
+}
+
+// transactions trả về các giao dịch hợp lệ với TU là tổng utility và TID theo thứ tự xuất hiện
+func (g *grouper) transactions() ([]*models.Transaction, *Report) {
+	var transactions []*models.Transaction
+	for _, transactionID := range g.order {
+		b := g.baskets[transactionID]
+		if b.invalid {
+			continue
+		}
+		transactionUtility := 0.0
+		for _, u := range b.utilities {
+			transactionUtility += u
+		}
+		if b.merged {
+			g.report.Merged++
+		}
+		transaction := models.NewTransaction(b.items, b.utilities, transactionUtility)
+		transaction.TID = len(transactions) + 1
+		transactions = append(transactions, transaction)
+	}
+	g.report.Transactions = len(transactions)
+	return transactions, g.report
+}
+
+// readCSV đọc CSV dạng dài; dòng đầu là tiêu đề chứa các cột của options.Columns
+func readCSV(r io.Reader, name string, options Options) ([]*models.Transaction, *Report, error) {
+	g := newGrouper(name, options)
+	bufferSize := options.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	reader := csv.NewReader(bufio.NewReaderSize(r, bufferSize))
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, g.report, nil
+	}
+	if err != nil {
+		return nil, g.report, fmt.Errorf("%s: reading header: %w", name, err)
+	}
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		index[strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))] = i
+	}
+	columns := options.columns()
+	var positions [3]int
+	for i, column := range []string{columns.Transaction, columns.Item, columns.Utility} {
+		position, found := index[column]
+		if !found {
+			return nil, g.report, fmt.Errorf("%s: header has no column %q", name, column)
+		}
+		positions[i] = position
+	}
+	g.report.Lines = 1
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		// FieldPos chỉ hợp lệ khi Read thành công nên số dòng của bản ghi lỗi lấy từ ParseError
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			g.report.Lines = parseErr.Line
+			if err := g.fail(parseErr.Line, "", KindFormat, "%v", parseErr.Err); err != nil {
+				return nil, g.report, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, g.report, fmt.Errorf("%s:%d: %w", name, g.report.Lines+1, err)
+		}
+		line, _ := reader.FieldPos(0)
+		g.report.Lines = line
+		if len(record) <= max(positions[0], positions[1], positions[2]) {
+			// Khi vẫn đọc được mã giao dịch, cả giao dịch bị bỏ như mọi dòng lỗi khác
+			transactionID := ""
+			if len(record) > positions[0] {
+				transactionID = strings.TrimSpace(record[positions[0]])
+			}
+			if err := g.fail(line, transactionID, KindFormat, "expected at least %d fields, found %d", max(positions[0], positions[1], positions[2])+1, len(record)); err != nil {
+				return nil, g.report, err
+			}
+			continue
+		}
+		if err := g.add(line, strings.TrimSpace(record[positions[0]]), strings.TrimSpace(record[positions[1]]), record[positions[2]]); err != nil {
+			return nil, g.report, err
+		}
+	}
+
+	transactions, report := g.transactions()
+	return transactions, report, nil
+}
+
+// readJSONL đọc JSON lines dạng dài, mỗi dòng một object có các trường của options.Columns.
+// Mã giao dịch và mã item có thể là chuỗi hoặc số; utility là số hoặc chuỗi chứa số.
+func readJSONL(r io.Reader, name string, options Options) ([]*models.Transaction, *Report, error) {
+	g := newGrouper(name, options)
+	columns := options.columns()
+	lines := NewLineReader(r, options.BufferSize)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := lines.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, g.report, fmt.Errorf("%s:%d: %w", name, lineNumber, err)
+		}
+		g.report.Lines = lineNumber
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		var row map[string]interface{}
+		decoder := json.NewDecoder(strings.NewReader(line))
+		decoder.UseNumber()
+		if err := decoder.Decode(&row); err != nil {
+			if err := g.fail(lineNumber, "", KindFormat, "invalid JSON object: %v", err); err != nil {
+				return nil, g.report, err
+			}
+			continue
+		}
+		fields := [3]string{}
+		missing := ""
+		for i, column := range []string{columns.Transaction, columns.Item, columns.Utility} {
+			switch value := row[column].(type) {
+			case string:
+				fields[i] = value
+			case json.Number:
+				fields[i] = value.String()
+			default:
+				missing = column
+			}
+		}
+		if missing != "" {
+			if err := g.fail(lineNumber, fields[0], KindFormat, "field %q is missing or not a string or number", missing); err != nil {
+				return nil, g.report, err
+			}
+			continue
+		}
+		if err := g.add(lineNumber, fields[0], fields[1], fields[2]); err != nil {
+			return nil, g.report, err
+		}
+	}
+
+	transactions, report := g.transactions()
+	return transactions, report, nil
+}
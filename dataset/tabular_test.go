@@ -0,0 +1,167 @@
+package dataset
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadCSVAndJSONL(t *testing.T) {
+	csvInput := strings.Join([]string{
+		"utility,basket,sku,store",
+		"5,t1,apple,A",
+		"-2,t2,\"pear, green\",A",
+		"3,t1,milk,A",
+		"1.5,t2,apple,B",
+	}, "\n")
+	jsonlInput := strings.Join([]string{
+		`{"basket": "t1", "sku": "apple", "utility": 5}`,
+		`{"basket": "t2", "sku": "pear, green", "utility": -2}`,
+		``,
+		`{"basket": "t1", "sku": "milk", "utility": "3"}`,
+		`{"basket": "t2", "sku": "apple", "utility": 1.5}`,
+	}, "\n")
+	columns := Columns{Transaction: "basket", Item: "sku", Utility: "utility"}
+
+	for format, input := range map[Format]string{FormatCSV: csvInput, FormatJSONL: jsonlInput} {
+		dictionary := NewDictionary()
+		transactions, report, err := Read(strings.NewReader(input), "rows", Options{Format: format, Columns: columns, Dictionary: dictionary})
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		apple, _ := dictionary.Lookup("apple")
+		pear, _ := dictionary.Lookup("pear, green")
+		milk, _ := dictionary.Lookup("milk")
+		if len(transactions) != 2 || report.Transactions != 2 || dictionary.Len() != 3 {
+			t.Fatalf("%s: got %d transactions, dictionary of %d codes", format, len(transactions), dictionary.Len())
+		}
+		t1, t2 := transactions[0], transactions[1]
+		if t1.TID != 1 || len(t1.Items) != 2 || t1.Items[0] != apple || t1.Items[1] != milk || t1.TransactionUtility != 8 {
+			t.Errorf("%s: first transaction %v", format, t1)
+		}
+		if t2.Items[0] != pear || t2.Items[1] != apple || t2.Utilities[1] != 1.5 || t2.TransactionUtility != -0.5 {
+			t.Errorf("%s: second transaction %v", format, t2)
+		}
+	}
+}
+
+func TestTabularValidation(t *testing.T) {
+	input := "transaction_id,item,utility\n1,a,2\n2,b,x\n1,c,1\n2,c,4\n3,d,1\n3,d,2\n4,e,1\n"
+
+	_, _, err := Read(strings.NewReader(input), "rows.csv", Options{Format: FormatCSV})
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 3 || lineErr.Kind != KindNumber {
+		t.Fatalf("strict: got %v, want a number error at line 3", err)
+	}
+
+	transactions, report, err := Read(strings.NewReader(input), "rows.csv", Options{Format: FormatCSV, Mode: Lenient})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Giao dịch 2 và 3 có dòng lỗi nên bị bỏ toàn bộ
+	if len(transactions) != 2 || len(transactions[0].Items) != 2 || transactions[1].TID != 2 || transactions[1].TransactionUtility != 1 {
+		t.Fatalf("lenient: got %v", transactions)
+	}
+	if len(report.Errors) != 2 || report.Errors[1].Line != 7 || report.Errors[1].Kind != KindDuplicateItem {
+		t.Errorf("lenient: unexpected errors %+v", report.Errors)
+	}
+
+	transactions, report, err = Read(strings.NewReader("transaction_id,item,utility\n3,d,1\n3,e,4\n3,d,2\n"), "rows.csv",
+		Options{Format: FormatCSV, MergeDuplicates: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 1 || len(transactions[0].Items) != 2 || transactions[0].Utilities[0] != 3 || report.Merged != 1 {
+		t.Errorf("merge: got %v, report %+v", transactions, report)
+	}
+
+	if _, _, err := Read(strings.NewReader("id,item,utility\n1,a,2\n"), "rows.csv", Options{Format: FormatCSV}); err == nil {
+		t.Error("missing transaction_id column accepted")
+	}
+}
+
+func TestCSVShortRowDropsTransaction(t *testing.T) {
+	input := "transaction_id,item,utility\n1,a,2\n1,b\n2,c,3\n"
+	transactions, report, err := Read(strings.NewReader(input), "rows.csv", Options{Format: FormatCSV, Mode: Lenient})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Dòng 3 thiếu cột utility nhưng có mã giao dịch nên giao dịch 1 bị bỏ toàn bộ
+	if len(transactions) != 1 || transactions[0].Items[0] != 2 || len(report.Errors) != 1 || report.Errors[0].Line != 3 {
+		t.Errorf("got %v, errors %+v", transactions, report.Errors)
+	}
+}
+
+func TestCSVBadQuote(t *testing.T) {
+	input := "transaction_id,item,utility\n1,a,2\n2,a\"b,1\n2,c,3\n\"ab\"c,d,1\n"
+
+	_, _, err := Read(strings.NewReader(input), "rows.csv", Options{Format: FormatCSV})
+	var lineErr *LineError
+	if !errors.As(err, &lineErr) || lineErr.Line != 3 || lineErr.Kind != KindFormat {
+		t.Fatalf("strict: got %v, want a format error at line 3", err)
+	}
+
+	transactions, report, err := Read(strings.NewReader(input), "rows.csv", Options{Format: FormatCSV, Mode: Lenient})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transactions) != 2 || len(transactions[1].Items) != 1 || transactions[1].Utilities[0] != 3 {
+		t.Fatalf("lenient: got %v", transactions)
+	}
+	if len(report.Errors) != 2 || report.Errors[0].Line != 3 || report.Errors[1].Line != 5 {
+		t.Errorf("lenient: unexpected errors %+v", report.Errors)
+	}
+}
+
+func TestDictionaryPersistence(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "items.dict")
+	dictionary, err := LoadDictionary(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dictionary.ID("apple") != 1 || dictionary.ID("milk") != 2 || dictionary.ID("apple") != 1 || !dictionary.Modified() {
+		t.Fatal("unexpected IDs for a new dictionary")
+	}
+	if err := dictionary.Save(fileName); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := LoadDictionary(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := reloaded.Lookup("milk"); id != 2 || reloaded.ID("tea") != 3 {
+		t.Errorf("reloaded dictionary does not keep the saved IDs")
+	}
+	if code, _ := reloaded.Code(1); code != "apple" {
+		t.Errorf("got code %q for ID 1", code)
+	}
+
+	var buf bytes.Buffer
+	if err := reloaded.Write(&buf); err != nil || buf.String() != "1\tapple\n2\tmilk\n3\ttea\n" {
+		t.Errorf("wrote %q (error %v)", buf.String(), err)
+	}
+	if _, err := ReadDictionary(strings.NewReader("1\ta\n1\tb\n"), "dup"); err == nil {
+		t.Error("duplicate ID accepted")
+	}
+}
+
+func TestReadItemNames(t *testing.T) {
+	names, err := ReadItemNames(strings.NewReader("# id name category\n1551\tGreen tea 500ml\tDrinks\n7\tApple\n"), "items.names")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names.Name(1551) != "Green tea 500ml" || names.Category(1551) != "Drinks" || names.Category(7) != "" || names.Name(8) != "8" {
+		t.Errorf("unexpected names %v", names)
+	}
+	if got := names.FormatItemset([]int{7, 1551}); got != "[Apple, Green tea 500ml]" {
+		t.Errorf("got %q", got)
+	}
+	for _, input := range []string{"1 Apple\n", "x\tApple\n", "1\tApple\n1\tPear\n"} {
+		if _, err := ReadItemNames(strings.NewReader(input), "items.names"); err == nil {
+			t.Errorf("%q accepted", input)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package formats
+
+import (
+	"EMHUNer/models"
+	"bufio"
+	"encoding/csv"
+	"strconv"
+	"strings"
+)
+
+// csvWriter ghi mỗi itemset thành một dòng "itemset,utility,support,length,average_utility,
+// min_utility,max_utility,tids,names", trong đó các item và các TID cách nhau bởi dấu cách; tids kết thúc
+// bằng "..." khi danh sách bị cắt bớt; names là tên các item ngăn bởi "; " (rỗng nếu không có từ điển
+// tên). Định dạng này không có metadata.
+type csvWriter struct {
+	w      *bufio.Writer
+	csv    *csv.Writer
+	header bool
+}
+
+func newCSVWriter(w *bufio.Writer) *csvWriter {
+	return &csvWriter{w: w, csv: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+	return c.csv.Write([]string{"itemset", "utility", "support", "length", "average_utility", "min_utility", "max_utility", "tids", "names"})
+}
+
+func (c *csvWriter) Emit(hui *models.HighUtilityItemset) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	tids := joinInts(hui.TIDs)
+	if hui.TIDsTruncated {
+		tids += " ..."
+	}
+	return c.csv.Write([]string{
+		joinInts(hui.Itemset),
+		formatUtility(hui.Utility),
+		strconv.Itoa(hui.Support),
+		strconv.Itoa(len(hui.Itemset)),
+		formatUtility(hui.AverageUtility()),
+		formatUtility(hui.MinUtility),
+		formatUtility(hui.MaxUtility),
+		tids,
+		strings.Join(itemLabels(hui, hui.Names.Name), "; "),
+	})
+}
+
+func (c *csvWriter) Close(*Metadata) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.csv.Flush()
+	if err := c.csv.Error(); err != nil {
+		return err
+	}
+	return c.w.Flush()
+}
+
+// joinInts nối các số nguyên bằng dấu cách
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.Itoa(value)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,71 @@
+package formats
+
+import (
+	"EMHUNer/algorithms"
+	"EMHUNer/models"
+	"EMHUNer/profiler"
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Metadata là thông tin của lần chạy được ghi cùng kết quả (ở các định dạng có chỗ cho nó)
+type Metadata struct {
+	Dataset           string
+	MinUtility        float64 // ngưỡng tuyệt đối cuối cùng (ở top-k là utility của itemset thứ k)
+	MinUtilityRatio   float64
+	TotalUtility      float64
+	TopK              int
+	Closed            bool
+	Maximal           bool
+	Streamed          bool // các HUI được ghi ngay khi tìm thấy, theo thứ tự tìm thấy
+	Incomplete        bool
+	CompletedBranches []int
+	ElapsedSeconds    float64
+	Memory            profiler.Report
+	Stats             algorithms.Stats
+}
+
+// Writer ghi kết quả khai thác theo một định dạng. Mỗi HUI được ghi ngay khi Emit, nên Writer
+// dùng được làm algorithms.Sink; Close ghi phần còn lại (metadata) và Flush nhưng không đóng io.Writer.
+type Writer interface {
+	Emit(hui *models.HighUtilityItemset) error
+	Close(meta *Metadata) error
+}
+
+// Names là tên các định dạng được hỗ trợ
+var Names = []string{"text", "json", "csv", "spmf"}
+
+// New tạo Writer cho định dạng name ghi vào w. Với closed, định dạng text và spmf ghi thêm support
+// (csv và json luôn có support).
+func New(name string, w io.Writer, closed bool) (Writer, error) {
+	buffered := bufio.NewWriter(w)
+	switch name {
+	case "text":
+		return &textWriter{w: buffered, sink: algorithms.NewFileSink(buffered, closed)}, nil
+	case "json":
+		return &jsonWriter{w: buffered}, nil
+	case "csv":
+		return newCSVWriter(buffered), nil
+	case "spmf":
+		return &spmfWriter{w: buffered, withSupport: closed}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q", name)
+}
+
+// Extension trả về phần mở rộng tên file thường dùng của định dạng name
+func Extension(name string) string {
+	switch name {
+	case "json":
+		return ".json"
+	case "csv":
+		return ".csv"
+	}
+	return ".txt"
+}
+
+// formatUtility ghi utility ở dạng ngắn nhất đọc lại được chính xác (37 thay vì 37.00)
+func formatUtility(utility float64) string {
+	return strconv.FormatFloat(utility, 'f', -1, 64)
+}
@@ -0,0 +1,143 @@
+package formats
+
+import (
+	"EMHUNer/algorithms"
+	"EMHUNer/models"
+	"EMHUNer/profiler"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testResults() ([]*models.HighUtilityItemset, *Metadata) {
+	a := models.NewHighUtilityItemset([]int{3, 4, 5}, 37)
+	a.Support, a.MinUtility, a.MaxUtility = 3, 10, 15
+	a.TIDs = []int{1, 4, 5}
+	b := models.NewHighUtilityItemset([]int{4}, 30.5)
+	b.Support, b.MinUtility, b.MaxUtility = 5, 2, 9.5
+	b.TIDs, b.TIDsTruncated = []int{1, 2}, true
+	b.Names = models.ItemNames{4: {Name: "green tea", Category: "drinks"}}
+	meta := &Metadata{
+		Dataset:        "data/table3.txt",
+		MinUtility:     30,
+		TotalUtility:   69,
+		Closed:         true,
+		ElapsedSeconds: 0.25,
+		Memory: profiler.Report{PeakHeap: 4096, PeakRSS: 8192, Allocated: 2048,
+			Phases: []profiler.PhaseUsage{{Name: "search", PeakHeap: 4096, PeakRSS: 8192, Allocated: 1024}}},
+		Stats: algorithms.Stats{
+			Transactions:        5,
+			HighUtilityItemsets: 2,
+			Search:              algorithms.SearchStats{Nodes: 6, NodesN: 8, PrunedRSU: 11},
+			Phases:              algorithms.PhaseTimings{Search: 1500 * time.Millisecond},
+		},
+	}
+	return []*models.HighUtilityItemset{a, b}, meta
+}
+
+func write(t *testing.T, format string, huis []*models.HighUtilityItemset, meta *Metadata) string {
+	t.Helper()
+	var buf bytes.Buffer
+	writer, err := New(format, &buf, meta.Closed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, hui := range huis {
+		if err := writer.Emit(hui); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(meta); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestJSONWriter(t *testing.T) {
+	huis, meta := testResults()
+	for _, huis := range [][]*models.HighUtilityItemset{huis, nil} {
+		var decoded struct {
+			Itemsets []jsonItemset `json:"itemsets"`
+			Metadata jsonMetadata  `json:"metadata"`
+		}
+		out := write(t, "json", huis, meta)
+		if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, out)
+		}
+		if len(decoded.Itemsets) != len(huis) {
+			t.Fatalf("got %d itemsets, want %d", len(decoded.Itemsets), len(huis))
+		}
+		for i, hui := range huis {
+			got := decoded.Itemsets[i]
+			if got.Utility != hui.Utility || got.Support != hui.Support || got.Length != len(hui.Itemset) ||
+				got.AverageUtility != hui.AverageUtility() || got.MaxUtility != hui.MaxUtility ||
+				fmt.Sprint(got.TIDs) != fmt.Sprint(hui.TIDs) || got.TIDsTruncated != hui.TIDsTruncated ||
+				fmt.Sprint(got.Names, got.Categories) != fmt.Sprint(itemLabels(hui, hui.Names.Name), itemLabels(hui, hui.Names.Category)) {
+				t.Errorf("itemset %d: got %+v, want %v", i, got, hui)
+			}
+		}
+		m := decoded.Metadata
+		if m.Dataset != meta.Dataset || m.MinUtility != 30 || !m.Closed || m.HighUtilityItemsets != 2 ||
+			m.Search.Nodes != 6 || m.Search.PrunedRSU != 11 || m.Phases.Search != 1.5 ||
+			m.Memory.PeakHeapBytes != 4096 || len(m.Memory.Phases) != 1 {
+			t.Errorf("unexpected metadata %+v", m)
+		}
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	huis, meta := testResults()
+	rows, err := csv.NewReader(strings.NewReader(write(t, "csv", huis, meta))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"itemset", "utility", "support", "length", "average_utility", "min_utility", "max_utility", "tids", "names"},
+		{"3 4 5", "37", "3", "3", "12.333333333333334", "10", "15", "1 4 5", ""},
+		{"4", "30.5", "5", "1", "6.1", "2", "9.5", "1 2 ...", "green tea"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(want))
+	}
+	for i := range want {
+		if strings.Join(rows[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("row %d: got %v, want %v", i, rows[i], want[i])
+		}
+	}
+
+	if out := write(t, "csv", nil, meta); out != "itemset,utility,support,length,average_utility,min_utility,max_utility,tids,names\n" {
+		t.Errorf("empty result wrote %q", out)
+	}
+}
+
+func TestSPMFAndTextWriters(t *testing.T) {
+	huis, meta := testResults()
+	if got, want := write(t, "spmf", huis, meta), "3 4 5 #SUP: 3 #UTIL: 37\n4 #SUP: 5 #UTIL: 30.5\n"; got != want {
+		t.Errorf("spmf: got %q, want %q", got, want)
+	}
+	meta.Closed = false
+	if got, want := write(t, "spmf", huis, meta), "3 4 5 #UTIL: 37\n4 #UTIL: 30.5\n"; got != want {
+		t.Errorf("spmf: got %q, want %q", got, want)
+	}
+
+	text := write(t, "text", huis, meta)
+	for _, line := range []string{
+		"Itemset: [3 4 5], Utility: 37.00\n",
+		"Itemset: [green tea], Utility: 30.50\n",
+		"Ngưỡng minUtility: 30.00\n",
+		"Bộ nhớ sử dụng: 4 KB\n",
+		"Số nút đã duyệt: 14 (Search: 6, SearchN: 8), độ sâu tối đa: 0\n",
+	} {
+		if !strings.Contains(text, line) {
+			t.Errorf("text output is missing %q:\n%s", line, text)
+		}
+	}
+
+	if _, err := New("xml", &bytes.Buffer{}, false); err == nil {
+		t.Error("New accepted an unknown format")
+	}
+}
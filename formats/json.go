@@ -0,0 +1,208 @@
+package formats
+
+import (
+	"EMHUNer/models"
+	"bufio"
+	"encoding/json"
+)
+
+// jsonWriter ghi một đối tượng JSON {"itemsets": [...], "metadata": {...}}. Các itemset được ghi
+// ngay khi nhận, metadata được ghi sau cùng nên định dạng này cũng dùng được khi ghi trực tiếp.
+type jsonWriter struct {
+	w     *bufio.Writer
+	count int
+}
+
+type jsonItemset struct {
+	Itemset        []int   `json:"itemset"`
+	Utility        float64 `json:"utility"`
+	Support        int     `json:"support"`
+	Length         int     `json:"length"`
+	AverageUtility float64 `json:"averageUtility"`
+	MinUtility     float64 `json:"minUtility"`
+	MaxUtility     float64 `json:"maxUtility"`
+	TIDs           []int   `json:"tids,omitempty"`
+	TIDsTruncated  bool    `json:"tidsTruncated,omitempty"`
+	// Names và Categories song song với Itemset, chỉ có khi dùng từ điển tên item
+	Names      []string `json:"names,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+}
+
+type jsonMetadata struct {
+	Dataset             string     `json:"dataset"`
+	MinUtility          float64    `json:"minUtility"`
+	MinUtilityRatio     float64    `json:"minUtilityRatio,omitempty"`
+	TopK                int        `json:"topK,omitempty"`
+	TotalUtility        float64    `json:"totalUtility"`
+	Closed              bool       `json:"closed"`
+	Maximal             bool       `json:"maximal"`
+	Incomplete          bool       `json:"incomplete"`
+	CompletedBranches   []int      `json:"completedBranches,omitempty"`
+	Transactions        int        `json:"transactions"`
+	HighUtilityItemsets int        `json:"highUtilityItemsets"`
+	ElapsedSeconds      float64    `json:"elapsedSeconds"`
+	Memory              jsonMemory `json:"memory"`
+	Items               jsonItems  `json:"items"`
+	Search              jsonSearch `json:"search"`
+	Phases              jsonPhases `json:"phaseSeconds"`
+}
+
+type jsonMemory struct {
+	PeakHeapBytes  uint64            `json:"peakHeapBytes"`
+	PeakRSSBytes   uint64            `json:"peakRSSBytes"`
+	AllocatedBytes uint64            `json:"allocatedBytes"`
+	Phases         []jsonMemoryPhase `json:"phases"`
+}
+
+type jsonMemoryPhase struct {
+	Name           string `json:"name"`
+	PeakHeapBytes  uint64 `json:"peakHeapBytes"`
+	PeakRSSBytes   uint64 `json:"peakRSSBytes"`
+	AllocatedBytes uint64 `json:"allocatedBytes"`
+}
+
+type jsonItems struct {
+	Rho       int `json:"rho"`
+	Delta     int `json:"delta"`
+	Eta       int `json:"eta"`
+	Secondary int `json:"secondary"`
+	Primary   int `json:"primary"`
+}
+
+type jsonSearch struct {
+	Nodes                 int64 `json:"nodes"`
+	NodesN                int64 `json:"nodesN"`
+	PrunedRTWU            int64 `json:"prunedRTWU"`
+	PrunedRSU             int64 `json:"prunedRSU"`
+	PrunedRLU             int64 `json:"prunedRLU"`
+	PrunedSubsumed        int64 `json:"prunedSubsumed"`
+	ProjectedTransactions int64 `json:"projectedTransactions"`
+	MaxDepth              int64 `json:"maxDepth"`
+}
+
+type jsonPhases struct {
+	Classification float64 `json:"classification"`
+	RTWU           float64 `json:"rtwu"`
+	Filtering      float64 `json:"filtering"`
+	Sorting        float64 `json:"sorting"`
+	RSU            float64 `json:"rsu"`
+	Search         float64 `json:"search"`
+}
+
+func (j *jsonWriter) Emit(hui *models.HighUtilityItemset) error {
+	prefix := ",\n    "
+	if j.count == 0 {
+		prefix = "{\n  \"itemsets\": [\n    "
+	}
+	j.count++
+	data, err := json.Marshal(jsonItemset{
+		Itemset:        hui.Itemset,
+		Utility:        hui.Utility,
+		Support:        hui.Support,
+		Length:         len(hui.Itemset),
+		AverageUtility: hui.AverageUtility(),
+		MinUtility:     hui.MinUtility,
+		MaxUtility:     hui.MaxUtility,
+		TIDs:           hui.TIDs,
+		TIDsTruncated:  hui.TIDsTruncated,
+		Names:          itemLabels(hui, hui.Names.Name),
+		Categories:     itemLabels(hui, hui.Names.Category),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := j.w.WriteString(prefix); err != nil {
+		return err
+	}
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonWriter) Close(meta *Metadata) error {
+	opening := "\n  ],\n"
+	if j.count == 0 {
+		opening = "{\n  \"itemsets\": [],\n"
+	}
+	if _, err := j.w.WriteString(opening + "  \"metadata\": "); err != nil {
+		return err
+	}
+
+	stats, search, phases := meta.Stats, meta.Stats.Search, meta.Stats.Phases
+	metadata := jsonMetadata{
+		Dataset:             meta.Dataset,
+		MinUtility:          meta.MinUtility,
+		MinUtilityRatio:     meta.MinUtilityRatio,
+		TopK:                meta.TopK,
+		TotalUtility:        meta.TotalUtility,
+		Closed:              meta.Closed,
+		Maximal:             meta.Maximal,
+		Incomplete:          meta.Incomplete,
+		CompletedBranches:   meta.CompletedBranches,
+		Transactions:        stats.Transactions,
+		HighUtilityItemsets: stats.HighUtilityItemsets,
+		ElapsedSeconds:      meta.ElapsedSeconds,
+		Memory: jsonMemory{
+			PeakHeapBytes:  meta.Memory.PeakHeap,
+			PeakRSSBytes:   meta.Memory.PeakRSS,
+			AllocatedBytes: meta.Memory.Allocated,
+			Phases:         []jsonMemoryPhase{},
+		},
+		Items: jsonItems{Rho: stats.Rho, Delta: stats.Delta, Eta: stats.Eta, Secondary: stats.Secondary, Primary: stats.Primary},
+		Search: jsonSearch{
+			Nodes:                 search.Nodes,
+			NodesN:                search.NodesN,
+			PrunedRTWU:            search.PrunedRTWU,
+			PrunedRSU:             search.PrunedRSU,
+			PrunedRLU:             search.PrunedRLU,
+			PrunedSubsumed:        search.PrunedSubsumed,
+			ProjectedTransactions: search.ProjectedTransactions,
+			MaxDepth:              search.MaxDepth,
+		},
+		Phases: jsonPhases{
+			Classification: phases.Classification.Seconds(),
+			RTWU:           phases.RTWU.Seconds(),
+			Filtering:      phases.Filtering.Seconds(),
+			Sorting:        phases.Sorting.Seconds(),
+			RSU:            phases.RSU.Seconds(),
+			Search:         phases.Search.Seconds(),
+		},
+	}
+	for _, phase := range meta.Memory.Phases {
+		metadata.Memory.Phases = append(metadata.Memory.Phases, jsonMemoryPhase{
+			Name:           phase.Name,
+			PeakHeapBytes:  phase.PeakHeap,
+			PeakRSSBytes:   phase.PeakRSS,
+			AllocatedBytes: phase.Allocated,
+		})
+	}
+
+	data, err := json.MarshalIndent(metadata, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := j.w.Write(data); err != nil {
+		return err
+	}
+	if _, err := j.w.WriteString("\n}\n"); err != nil {
+		return err
+	}
+	return j.w.Flush()
+}
+
+// itemLabels trả về label của từng item của hui, nil nếu hui không có từ điển tên
+// hoặc mọi label đều rỗng
+func itemLabels(hui *models.HighUtilityItemset, label func(int) string) []string {
+	if hui.Names == nil {
+		return nil
+	}
+	labels := make([]string, len(hui.Itemset))
+	empty := true
+	for i, item := range hui.Itemset {
+		labels[i] = label(item)
+		empty = empty && labels[i] == ""
+	}
+	if empty {
+		return nil
+	}
+	return labels
+}
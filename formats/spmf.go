@@ -0,0 +1,36 @@
+package formats
+
+import (
+	"EMHUNer/models"
+	"bufio"
+	"strconv"
+)
+
+// spmfWriter ghi định dạng kết quả của thư viện SPMF: "1 2 3 #UTIL: 37", thêm "#SUP: n" trước
+// #UTIL khi ghi HUI đóng. Định dạng này không có metadata.
+type spmfWriter struct {
+	w           *bufio.Writer
+	withSupport bool
+}
+
+func (s *spmfWriter) Emit(hui *models.HighUtilityItemset) error {
+	line := make([]byte, 0, 8*len(hui.Itemset)+16)
+	for _, item := range hui.Itemset {
+		line = strconv.AppendInt(line, int64(item), 10)
+		line = append(line, ' ')
+	}
+	if s.withSupport {
+		line = append(line, "#SUP: "...)
+		line = strconv.AppendInt(line, int64(hui.Support), 10)
+		line = append(line, ' ')
+	}
+	line = append(line, "#UTIL: "...)
+	line = append(line, formatUtility(hui.Utility)...)
+	line = append(line, '\n')
+	_, err := s.w.Write(line)
+	return err
+}
+
+func (s *spmfWriter) Close(*Metadata) error {
+	return s.w.Flush()
+}
@@ -0,0 +1,120 @@
+package formats
+
+import (
+	"EMHUNer/algorithms"
+	"EMHUNer/models"
+	"EMHUNer/profiler"
+	"bufio"
+	"fmt"
+)
+
+// textWriter ghi định dạng văn bản truyền thống: các dòng "Itemset: [...], Utility: ..." rồi tới
+// ngưỡng, thời gian, bộ nhớ và thống kê tìm kiếm
+type textWriter struct {
+	w    *bufio.Writer
+	sink *algorithms.FileSink
+}
+
+func (t *textWriter) Emit(hui *models.HighUtilityItemset) error {
+	return t.sink.Emit(hui)
+}
+
+// Close ghi phần thông tin sau danh sách HUI (ngưỡng, chế độ, thời gian, bộ nhớ, thống kê) và Flush
+func (t *textWriter) Close(meta *Metadata) error {
+	writer := t.w
+	// Ghi ngưỡng minUtility đã sử dụng (kèm tỉ lệ hoặc k nếu dùng ngưỡng tương đối hay top-k)
+	threshold := fmt.Sprintf("\nNgưỡng minUtility: %.2f\n", meta.MinUtility)
+	if meta.TopK > 0 {
+		threshold = fmt.Sprintf("\nNgưỡng minUtility: %.2f (top-%d)\n", meta.MinUtility, meta.TopK)
+	} else if meta.MinUtilityRatio > 0 {
+		threshold = fmt.Sprintf("\nNgưỡng minUtility: %.2f (%.4f%% của tổng utility dương %.2f)\n",
+			meta.MinUtility, meta.MinUtilityRatio*100, meta.TotalUtility)
+	}
+	if meta.Closed {
+		threshold += "Chỉ giữ các HUI đóng (closed)\n"
+	}
+	if meta.Maximal {
+		threshold += "Chỉ giữ các HUI cực đại (maximal)\n"
+	}
+	if meta.Streamed {
+		threshold += fmt.Sprintf("Số HUI: %d\n", meta.Stats.HighUtilityItemsets)
+	}
+	if meta.Incomplete {
+		threshold += fmt.Sprintf("Kết quả chưa đầy đủ (dừng sớm), các nhánh đã hoàn tất: %v\n", meta.CompletedBranches)
+	}
+	_, err := writer.WriteString(threshold)
+	if err != nil {
+		return err
+	}
+
+	// Ghi thông tin về thời gian (theo giây) và bộ nhớ
+	_, err = writer.WriteString(fmt.Sprintf("Thời gian chạy thuật toán: %.6f giây\n", meta.ElapsedSeconds))
+	if err != nil {
+		return err
+	}
+
+	// Bộ nhớ sử dụng là đỉnh heap đang dùng, lấy mẫu trong suốt lần chạy
+	_, err = writer.WriteString(fmt.Sprintf("Bộ nhớ sử dụng: %d KB\n", meta.Memory.PeakHeap/1024))
+	if err != nil {
+		return err
+	}
+
+	if err := writeMemoryReport(writer, meta.Memory); err != nil {
+		return err
+	}
+
+	if err := writeSearchStats(writer, meta.Stats); err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+// writeMemoryReport ghi đỉnh RSS, tổng cấp phát và mức dùng bộ nhớ của từng giai đoạn (theo KB)
+func writeMemoryReport(writer *bufio.Writer, memory profiler.Report) error {
+	_, err := writer.WriteString(fmt.Sprintf("Đỉnh RSS: %d KB, tổng cấp phát: %d KB\n", memory.PeakRSS/1024, memory.Allocated/1024))
+	if err != nil {
+		return err
+	}
+	_, err = writer.WriteString("Bộ nhớ theo giai đoạn (đỉnh heap / đỉnh RSS / cấp phát, KB):")
+	if err != nil {
+		return err
+	}
+	for i, phase := range memory.Phases {
+		separator := ", "
+		if i == 0 {
+			separator = " "
+		}
+		_, err = writer.WriteString(fmt.Sprintf("%s%s %d/%d/%d", separator, phase.Name, phase.PeakHeap/1024, phase.PeakRSS/1024, phase.Allocated/1024))
+		if err != nil {
+			return err
+		}
+	}
+	_, err = writer.WriteString("\n")
+	return err
+}
+
+// writeSearchStats ghi kích thước các tập item, hình dạng cây tìm kiếm, số nhánh bị cắt tỉa
+// và thời gian từng giai đoạn, để so sánh hiệu năng giữa các ngưỡng
+func writeSearchStats(writer *bufio.Writer, stats algorithms.Stats) error {
+	search, phases := stats.Search, stats.Phases
+	lines := []string{
+		"\nThống kê tìm kiếm:\n",
+		fmt.Sprintf("|ρ| = %d, |δ| = %d, |η| = %d, |Secondary| = %d, |Primary| = %d\n",
+			stats.Rho, stats.Delta, stats.Eta, stats.Secondary, stats.Primary),
+		fmt.Sprintf("Số nút đã duyệt: %d (Search: %d, SearchN: %d), độ sâu tối đa: %d\n",
+			search.Nodes+search.NodesN, search.Nodes, search.NodesN, search.MaxDepth),
+		fmt.Sprintf("Số giao dịch chiếu đã tạo: %d\n", search.ProjectedTransactions),
+		fmt.Sprintf("Số nhánh bị cắt tỉa: RTWU %d, RSU %d, RLU %d, bị bao (maximal) %d\n",
+			search.PrunedRTWU, search.PrunedRSU, search.PrunedRLU, search.PrunedSubsumed),
+		fmt.Sprintf("Thời gian từng giai đoạn (giây): phân loại %.6f, RTWU %.6f, lọc %.6f, sắp xếp %.6f, RSU %.6f, tìm kiếm %.6f\n",
+			phases.Classification.Seconds(), phases.RTWU.Seconds(), phases.Filtering.Seconds(),
+			phases.Sorting.Seconds(), phases.RSU.Seconds(), phases.Search.Seconds()),
+	}
+	for _, line := range lines {
+		if _, err := writer.WriteString(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
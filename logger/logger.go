@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Level xác định mức độ chi tiết của thông tin được ghi
+type Level int
+
+const (
+	// None không ghi gì cả
+	None Level = iota
+	// Summary chỉ ghi các bước chính của thuật toán
+	Summary
+	// Node ghi chi tiết từng nút trong cây tìm kiếm
+	Node
+)
+
+func (l Level) String() string {
+	switch l {
+	case None:
+		return "none"
+	case Summary:
+		return "summary"
+	case Node:
+		return "node"
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// ParseLevel chuyển tên mức log (none, summary, node) thành Level
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "none":
+		return None, nil
+	case "summary":
+		return Summary, nil
+	case "node":
+		return Node, nil
+	}
+	return None, fmt.Errorf("unknown log level %q", name)
+}
+
+// Logger là điểm gắn log/tracer cho thuật toán. Mặc định thuật toán dùng Nop nên không in gì.
+type Logger interface {
+	// Enabled cho biết các thông điệp ở mức level có được ghi hay không,
+	// dùng để tránh tốn chi phí định dạng ở các vòng lặp nóng
+	Enabled(level Level) bool
+	// Logf ghi một thông điệp theo kiểu fmt.Printf
+	Logf(level Level, format string, args ...interface{})
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Enabled(Level) bool                 { return false }
+func (nopLogger) Logf(Level, string, ...interface{}) {}
+
+// Nop trả về Logger không ghi gì
+func Nop() Logger {
+	return nopLogger{}
+}
+
+type writerLogger struct {
+	mu    sync.Mutex
+	out   io.Writer
+	level Level
+}
+
+// New trả về Logger ghi ra out mọi thông điệp có mức không vượt quá level
+func New(out io.Writer, level Level) Logger {
+	if level == None {
+		return Nop()
+	}
+	return &writerLogger{out: out, level: level}
+}
+
+func (l *writerLogger) Enabled(level Level) bool {
+	return level != None && level <= l.level
+}
+
+func (l *writerLogger) Logf(level Level, format string, args ...interface{}) {
+	if !l.Enabled(level) {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.out, format, args...)
+}
@@ -2,141 +2,135 @@ package main
 
 import (
 	"EMHUNer/algorithms"
-	"EMHUNer/models"
-	"bufio"
+	"EMHUNer/formats"
+	"errors"
 	"fmt"
 	"os"
-	"runtime"
-	"strconv"
-	"strings"
-	"time"
 )
 
-func main() {
-	fileName := "data/BMS.txt"
-	minUtility := 2000000.0
+// Mã thoát của chương trình
+const (
+	exitOK            = 0
+	exitBadParameters = 2
+	exitBadInput      = 3
+	exitMiningFailure = 4
+	exitOutputFailure = 5
+	exitIncomplete    = 6
+)
 
-	// Đo thời gian bắt đầu
-	startTime := time.Now()
+// cliError gắn một lỗi với mã thoát tương ứng
+type cliError struct {
+	code int
+	err  error
+}
 
-	// Đo bộ nhớ trước khi chạy thuật toán
-	var memStatsBefore, memStatsAfter runtime.MemStats
-	runtime.ReadMemStats(&memStatsBefore)
+func (e *cliError) Error() string {
+	return e.err.Error()
+}
 
-	transactions, err := readTransactionsFromFile(fileName)
-	if err != nil {
-		fmt.Println("Error reading transactions:", err)
-		return
-	}
-	fmt.Println("Transactions vừa đọc được:")
-	for i, transaction := range transactions {
-		fmt.Printf("Transaction %d: %s\n", i+1, transaction)
-	}
-	emhun := algorithms.NewEMHUN(transactions, minUtility)
+func badParameters(format string, args ...interface{}) error {
+	return &cliError{code: exitBadParameters, err: fmt.Errorf(format, args...)}
+}
 
-	emhun.Run()
+func badInput(err error) error {
+	return &cliError{code: exitBadInput, err: err}
+}
 
-	elapsedTime := time.Since(startTime).Seconds()
-	fmt.Printf("\nThời gian chạy thuật toán: %.6f s\n", elapsedTime)
+func miningFailure(err error) error {
+	return &cliError{code: exitMiningFailure, err: err}
+}
 
-	// Đo bộ nhớ sau khi chạy thuật toán
-	runtime.ReadMemStats(&memStatsAfter)
-	allocatedMemory := (memStatsAfter.Alloc - memStatsBefore.Alloc) / 1024
-	fmt.Printf("Bộ nhớ sử dụng: %d KB\n", allocatedMemory)
+func outputFailure(err error) error {
+	return &cliError{code: exitOutputFailure, err: err}
+}
 
-	fmt.Println("\nFinished executing EMHUN algorithm.")
-	outputFileName := "output/BMS_2000000.txt"
-	err = writeResultsToFile(emhun, outputFileName, elapsedTime, allocatedMemory)
-	if err != nil {
-		fmt.Println("Error writing results:", err)
-		return
-	}
+func incompleteRun(err error) error {
+	return &cliError{code: exitIncomplete, err: err}
+}
 
-	fmt.Println("Finished executing EMHUN algorithm. Results written to", outputFileName)
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
 }
 
-func readTransactionsFromFile(fileName string) ([]*models.Transaction, error) {
-	file, err := os.Open(fileName)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
+var commands = []command{
+	{"mine", "mine high-utility itemsets from a dataset", runMine},
+	{"stats", "print statistics about a dataset", runStats},
+	{"convert", "rewrite a dataset (quantities, csv or jsonl) in the items:TU:utilities format", runConvert},
+	{"validate", "check every line of a dataset and report the invalid ones", runValidate},
+	{"stream", "mine a sliding window over a transaction feed", runStream},
+}
 
-	var transactions []*models.Transaction
+func main() {
+	os.Exit(runCLI(os.Args[1:]))
+}
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Split(line, ":")
-		if len(parts) != 3 {
-			fmt.Println("Invalid line format:", line)
-			continue
+func runCLI(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		printUsage()
+		if len(args) == 0 {
+			return exitBadParameters
 		}
+		return exitOK
+	}
 
-		itemsStr := strings.Fields(parts[0])
-		var items []int
-		for _, item := range itemsStr {
-			itemInt, err := strconv.Atoi(item)
-			if err != nil {
-				return nil, err
-			}
-			items = append(items, itemInt)
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
 		}
-
-		transUtility, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
-		if err != nil {
-			return nil, err
+		err := cmd.run(args[1:])
+		if err == nil {
+			return exitOK
 		}
-
-		utilitiesStr := strings.Fields(parts[2])
-		var utilities []float64
-		for _, utility := range utilitiesStr {
-			utilityFloat, err := strconv.ParseFloat(utility, 64)
-			if err != nil {
-				return nil, err
-			}
-			utilities = append(utilities, utilityFloat)
+		var cliErr *cliError
+		if errors.As(err, &cliErr) {
+			fmt.Fprintf(os.Stderr, "emhun %s: %v\n", cmd.name, cliErr.err)
+			return cliErr.code
 		}
-
-		// Tạo transaction với các số thực
-		transaction := models.NewTransaction(items, utilities, transUtility)
-		transactions = append(transactions, transaction)
+		fmt.Fprintf(os.Stderr, "emhun %s: %v\n", cmd.name, err)
+		return exitMiningFailure
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
+	fmt.Fprintf(os.Stderr, "emhun: unknown command %q\n\n", args[0])
+	printUsage()
+	return exitBadParameters
+}
 
-	return transactions, nil
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: emhun <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun 'emhun <command> -h' for the flags of a command.")
+	fmt.Fprintln(os.Stderr, "\nExit codes:")
+	fmt.Fprintf(os.Stderr, "  %d bad parameters, %d bad input, %d mining failure, %d output failure,\n",
+		exitBadParameters, exitBadInput, exitMiningFailure, exitOutputFailure)
+	fmt.Fprintf(os.Stderr, "  %d stopped early (timeout or interrupt), partial results written\n", exitIncomplete)
 }
-func writeResultsToFile(emhun *algorithms.EMHUN, fileName string, elapsedTime float64, allocatedMemory uint64) error {
+
+// writeResultsToFile ghi các HUI của result và metadata của lần chạy vào fileName theo định dạng format
+func writeResultsToFile(format string, result *algorithms.Result, fileName string, meta *formats.Metadata) (err error) {
 	file, err := os.Create(fileName)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	writer := bufio.NewWriter(file)
-
-	// Ghi kết quả thuật toán
-	for _, hui := range emhun.SearchAlgorithms.HighUtilityItemsets {
-		line := fmt.Sprintf("Itemset: %v, Utility: %.2f\n", hui.Itemset, hui.Utility)
-		_, err := writer.WriteString(line)
-		if err != nil {
-			return err
+	// Lỗi ghi có thể chỉ xuất hiện khi đóng file (đĩa đầy, NFS) nên không được bỏ qua
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
 		}
-	}
+	}()
 
-	// Ghi thông tin về thời gian (theo giây) và bộ nhớ
-	_, err = writer.WriteString(fmt.Sprintf("\nThời gian chạy thuật toán: %.6f giây\n", elapsedTime))
+	writer, err := formats.New(format, file, meta.Closed)
 	if err != nil {
 		return err
 	}
-
-	_, err = writer.WriteString(fmt.Sprintf("Bộ nhớ sử dụng: %d KB\n", allocatedMemory))
-	if err != nil {
-		return err
+	for _, hui := range result.HighUtilityItemsets.Items() {
+		if err := writer.Emit(hui); err != nil {
+			return err
+		}
 	}
-
-	return writer.Flush()
+	return writer.Close(meta)
 }
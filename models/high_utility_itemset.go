@@ -1,13 +1,16 @@
 package models
 
-import (
-	"fmt"
-	"strings"
-)
+import "fmt"
 
 type HighUtilityItemset struct {
-	Itemset []int      
-	Utility float64    
+	Itemset       []int
+	Utility       float64
+	Support       int       // số giao dịch chứa itemset
+	MinUtility    float64   // utility nhỏ nhất của itemset trong một giao dịch chứa nó
+	MaxUtility    float64   // utility lớn nhất của itemset trong một giao dịch chứa nó
+	TIDs          []int     // TID tăng dần của các giao dịch chứa itemset (nil nếu không thu thập)
+	TIDsTruncated bool      // TIDs chỉ giữ một phần đầu của danh sách do giới hạn bộ nhớ
+	Names         ItemNames // tên item dùng khi in kết quả (nil thì in ID)
 }
 
 func NewHighUtilityItemset(itemset []int, utility float64) *HighUtilityItemset {
@@ -25,6 +28,14 @@ func (hui *HighUtilityItemset) GetUtility() float64 {
 	return hui.Utility
 }
 
+// AverageUtility trả về utility trung bình của itemset trên mỗi giao dịch chứa nó
+func (hui *HighUtilityItemset) AverageUtility() float64 {
+	if hui.Support == 0 {
+		return 0
+	}
+	return hui.Utility / float64(hui.Support)
+}
+
 func (hui *HighUtilityItemset) String() string {
-	return fmt.Sprintf("Itemset: [%s], Utility: %.2f", strings.Trim(fmt.Sprint(hui.Itemset), "[]"), hui.Utility)
+	return fmt.Sprintf("Itemset: %s, Utility: %.2f", hui.Names.FormatItemset(hui.Itemset), hui.Utility)
 }
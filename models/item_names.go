@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ItemInfo là tên hiển thị và nhóm (tùy chọn) của một item
+type ItemInfo struct {
+	Name     string
+	Category string
+}
+
+// ItemNames ánh xạ ID gốc của item (ID trong dataset) sang tên hiển thị. Map nil hợp lệ:
+// mọi item được hiển thị bằng ID.
+type ItemNames map[int]ItemInfo
+
+// Name trả về tên của item, hoặc ID của nó nếu không có tên
+func (n ItemNames) Name(item int) string {
+	if info, found := n[item]; found && info.Name != "" {
+		return info.Name
+	}
+	return strconv.Itoa(item)
+}
+
+// Category trả về nhóm của item, chuỗi rỗng nếu không có
+func (n ItemNames) Category(item int) string {
+	return n[item].Category
+}
+
+// FormatItemset ghi itemset dạng "[1 2 3]", hoặc "[sữa, bánh mì]" khi có từ điển tên
+// (tên có thể chứa khoảng trắng nên được ngăn bởi dấu phẩy)
+func (n ItemNames) FormatItemset(itemset []int) string {
+	separator := ", "
+	if n == nil {
+		separator = " "
+	}
+	labels := make([]string, len(itemset))
+	for i, item := range itemset {
+		labels[i] = n.Name(item)
+	}
+	return "[" + strings.Join(labels, separator) + "]"
+}
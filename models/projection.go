@@ -0,0 +1,42 @@
+package models
+
+// ProjectedTransaction là một giao dịch trong cơ sở dữ liệu chiếu của itemset X, được giữ bằng con trỏ tới
+// giao dịch gốc thay vì sao chép item và utility. Item trong giao dịch tăng dần theo thứ tự xử lý nên
+// các item mở rộng của X chỉ nằm từ Offset (vị trí ngay sau item cuối của X) trở đi.
+type ProjectedTransaction struct {
+	Transaction *Transaction
+	Offset      int
+	Utility     float64 // utility của X trong giao dịch
+}
+
+// Projection là cơ sở dữ liệu chiếu của một itemset: mỗi giao dịch chứa itemset xuất hiện đúng một lần
+type Projection []ProjectedTransaction
+
+// NewProjection trả về cơ sở dữ liệu chiếu của itemset rỗng trên các giao dịch cho trước
+func NewProjection(transactions []*Transaction) Projection {
+	projection := make(Projection, len(transactions))
+	for i, transaction := range transactions {
+		projection[i] = ProjectedTransaction{Transaction: transaction}
+	}
+	return projection
+}
+
+// Transactions trả về các giao dịch gốc của cơ sở dữ liệu chiếu
+func (p Projection) Transactions() []*Transaction {
+	transactions := make([]*Transaction, len(p))
+	for i, projected := range p {
+		transactions[i] = projected.Transaction
+	}
+	return transactions
+}
+
+// PositiveUtility trả về tổng phần utility dương của itemset trên các giao dịch chiếu
+func (p Projection) PositiveUtility() float64 {
+	total := 0.0
+	for _, projected := range p {
+		if projected.Utility > 0 {
+			total += projected.Utility
+		}
+	}
+	return total
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SortOrder là thứ tự sắp xếp của ResultSet
+type SortOrder int
+
+const (
+	SortByUtility     SortOrder = iota // utility giảm dần
+	SortByLength                       // số item tăng dần
+	SortLexicographic                  // theo thứ tự từ điển của itemset đã chuẩn hóa
+)
+
+func (o SortOrder) String() string {
+	switch o {
+	case SortByUtility:
+		return "utility"
+	case SortByLength:
+		return "length"
+	case SortLexicographic:
+		return "lex"
+	}
+	return fmt.Sprintf("SortOrder(%d)", int(o))
+}
+
+// ParseSortOrder chuyển tên thứ tự ("utility", "length", "lex") thành SortOrder
+func ParseSortOrder(name string) (SortOrder, error) {
+	for _, order := range []SortOrder{SortByUtility, SortByLength, SortLexicographic} {
+		if order.String() == name {
+			return order, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown sort order %q", name)
+}
+
+// ResultSet là tập HUI đã chuẩn hóa: item trong mỗi itemset được sắp tăng dần và mỗi itemset
+// chỉ xuất hiện một lần, nên kết quả của các lần chạy có thể so sánh trực tiếp với nhau
+type ResultSet struct {
+	itemsets   []*HighUtilityItemset
+	index      map[string]int
+	duplicates []*HighUtilityItemset
+}
+
+func NewResultSet() *ResultSet {
+	return &ResultSet{index: make(map[string]int)}
+}
+
+// Add chuẩn hóa và thêm một HUI. Nếu itemset đã có trong tập, HUI bị từ chối,
+// được ghi nhận vào Duplicates và Add trả về false.
+func (r *ResultSet) Add(hui *HighUtilityItemset) bool {
+	canonical := *hui
+	canonical.Itemset = append([]int(nil), hui.Itemset...)
+	sort.Ints(canonical.Itemset)
+
+	key := fmt.Sprint(canonical.Itemset)
+	if _, exists := r.index[key]; exists {
+		r.duplicates = append(r.duplicates, &canonical)
+		return false
+	}
+	r.index[key] = len(r.itemsets)
+	r.itemsets = append(r.itemsets, &canonical)
+	return true
+}
+
+// Get trả về HUI có cùng các item với itemset (không phụ thuộc thứ tự), hoặc nil
+func (r *ResultSet) Get(itemset []int) *HighUtilityItemset {
+	sorted := append([]int(nil), itemset...)
+	sort.Ints(sorted)
+	if i, ok := r.index[fmt.Sprint(sorted)]; ok {
+		return r.itemsets[i]
+	}
+	return nil
+}
+
+func (r *ResultSet) Len() int {
+	return len(r.itemsets)
+}
+
+// Items trả về các HUI theo thứ tự hiện tại của tập
+func (r *ResultSet) Items() []*HighUtilityItemset {
+	return r.itemsets
+}
+
+// Duplicates trả về các HUI bị từ chối vì itemset đã có trong tập
+func (r *ResultSet) Duplicates() []*HighUtilityItemset {
+	return r.duplicates
+}
+
+// Sort sắp xếp tập theo order; các HUI bằng nhau theo tiêu chí chính được sắp theo thứ tự từ điển
+// nên thứ tự cuối cùng luôn xác định
+func (r *ResultSet) Sort(order SortOrder) {
+	sort.SliceStable(r.itemsets, func(i, j int) bool {
+		a, b := r.itemsets[i], r.itemsets[j]
+		switch order {
+		case SortByUtility:
+			if a.Utility != b.Utility {
+				return a.Utility > b.Utility
+			}
+		case SortByLength:
+			if len(a.Itemset) != len(b.Itemset) {
+				return len(a.Itemset) < len(b.Itemset)
+			}
+		}
+		return lessItemset(a.Itemset, b.Itemset)
+	})
+	for i, hui := range r.itemsets {
+		r.index[fmt.Sprint(hui.Itemset)] = i
+	}
+}
+
+// lessItemset so sánh hai itemset đã sắp xếp theo thứ tự từ điển
+func lessItemset(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
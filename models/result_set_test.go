@@ -0,0 +1,55 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestResultSetCanonicalisesAndRejectsDuplicates(t *testing.T) {
+	set := NewResultSet()
+	if !set.Add(NewHighUtilityItemset([]int{5, 3, 4}, 37)) {
+		t.Fatal("first itemset was rejected")
+	}
+	if set.Add(NewHighUtilityItemset([]int{4, 5, 3}, 37)) {
+		t.Fatal("duplicate itemset in a different order was accepted")
+	}
+	if set.Len() != 1 || len(set.Duplicates()) != 1 {
+		t.Fatalf("got %d itemsets and %d duplicates, want 1 and 1", set.Len(), len(set.Duplicates()))
+	}
+	if got := fmt.Sprint(set.Items()[0].Itemset); got != "[3 4 5]" {
+		t.Errorf("itemset stored as %s, want [3 4 5]", got)
+	}
+	if set.Get([]int{4, 3, 5}) == nil {
+		t.Error("Get did not find the itemset regardless of item order")
+	}
+}
+
+func TestResultSetSortIsDeterministic(t *testing.T) {
+	set := NewResultSet()
+	set.Add(NewHighUtilityItemset([]int{4}, 28))
+	set.Add(NewHighUtilityItemset([]int{5, 4}, 37))
+	set.Add(NewHighUtilityItemset([]int{3, 4, 5}, 37))
+	set.Add(NewHighUtilityItemset([]int{2, 4, 5}, 31))
+
+	tests := []struct {
+		order SortOrder
+		want  string
+	}{
+		{SortByUtility, "[[3 4 5] [4 5] [2 4 5] [4]]"},
+		{SortByLength, "[[4] [4 5] [2 4 5] [3 4 5]]"},
+		{SortLexicographic, "[[2 4 5] [3 4 5] [4] [4 5]]"},
+	}
+	for _, test := range tests {
+		set.Sort(test.order)
+		var itemsets [][]int
+		for _, hui := range set.Items() {
+			itemsets = append(itemsets, hui.Itemset)
+		}
+		if got := fmt.Sprint(itemsets); got != test.want {
+			t.Errorf("Sort(%s) = %s, want %s", test.order, got, test.want)
+		}
+		if set.Get([]int{4, 5}).Utility != 37 {
+			t.Errorf("Sort(%s) broke the index", test.order)
+		}
+	}
+}
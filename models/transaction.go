@@ -3,9 +3,10 @@ package models
 import "fmt"
 
 type Transaction struct {
-	Items              []int    
-	Utilities          []float64  
-	TransactionUtility float64   
+	TID                int // mã giao dịch ổn định, dùng để xóa/sửa giao dịch khi khai thác tăng dần
+	Items              []int
+	Utilities          []float64
+	TransactionUtility float64
 }
 
 func NewTransaction(items []int, utilities []float64, transUtility float64) *Transaction {
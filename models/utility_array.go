@@ -2,20 +2,55 @@ package models
 
 import "fmt"
 
+// UtilityArray lưu RTWU, RLU và RSU theo chỉ số item liên tục 0..n-1 (các item đã được đánh lại số
+// theo thứ tự xử lý), nên mỗi lần tra cứu là một phép truy cập mảng
 type UtilityArray struct {
-	RTWUs map[int]float64
-	RLUs  map[int]float64
-	RSUs  map[int]float64
+	RTWUs []float64
+	RLUs  []float64
+	RSUs  []float64
 }
 
-func NewUtilityArray() *UtilityArray {
+func NewUtilityArray(size int) *UtilityArray {
+	// Ba mảng dùng chung một vùng nhớ để mỗi nút tìm kiếm chỉ cấp phát một lần
+	values := make([]float64, 3*size)
 	return &UtilityArray{
-		RTWUs: make(map[int]float64),
-		RLUs:  make(map[int]float64),
-		RSUs:  make(map[int]float64),
+		RTWUs: values[:size:size],
+		RLUs:  values[size : 2*size : 2*size],
+		RSUs:  values[2*size:],
 	}
 }
 
+// NewBoundArray tạo UtilityArray chỉ có RLU và RSU, dùng cho các cận của một nút tìm kiếm
+func NewBoundArray(size int) *UtilityArray {
+	values := make([]float64, 2*size)
+	return &UtilityArray{
+		RLUs: values[:size:size],
+		RSUs: values[size:],
+	}
+}
+
+// Reset đặt lại kích thước mảng thành size và xóa mọi giá trị, dùng lại vùng nhớ cũ khi đủ chỗ
+func (ua *UtilityArray) Reset(size int) {
+	ua.RTWUs = resize(ua.RTWUs, size)
+	ua.RLUs = resize(ua.RLUs, size)
+	ua.RSUs = resize(ua.RSUs, size)
+}
+
+func (ua *UtilityArray) Len() int {
+	return len(ua.RSUs)
+}
+
+func resize(values []float64, size int) []float64 {
+	if cap(values) < size {
+		return make([]float64, size)
+	}
+	values = values[:size]
+	for i := range values {
+		values[i] = 0
+	}
+	return values
+}
+
 // Setters and Getters for RTWU
 func (ua *UtilityArray) SetRTWU(item int, value float64) {
 	ua.RTWUs[item] = value
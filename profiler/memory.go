@@ -0,0 +1,198 @@
+package profiler
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultInterval là chu kỳ lấy mẫu mặc định của MemoryProfiler
+const DefaultInterval = 10 * time.Millisecond
+
+// PhaseUsage là mức dùng bộ nhớ trong một giai đoạn (theo byte). Một giai đoạn có thể được bắt đầu
+// nhiều lần; các đỉnh được lấy lớn nhất và lượng cấp phát được cộng dồn qua mọi lần.
+type PhaseUsage struct {
+	Name      string
+	PeakHeap  uint64 // đỉnh heap đang dùng (HeapInuse)
+	PeakRSS   uint64 // đỉnh RSS của tiến trình (0 nếu hệ điều hành không cung cấp)
+	Allocated uint64 // tổng byte được cấp phát trong giai đoạn (chênh lệch TotalAlloc)
+}
+
+// Report là kết quả đo của một lần chạy
+type Report struct {
+	PeakHeap  uint64 // đỉnh heap đang dùng trong cả lần chạy
+	PeakRSS   uint64 // đỉnh RSS lấy mẫu được, hoặc VmHWM của tiến trình nếu lớn hơn
+	Allocated uint64 // tổng byte được cấp phát từ Start đến Stop
+	Phases    []PhaseUsage
+}
+
+// MemoryProfiler lấy mẫu heap đang dùng, TotalAlloc và RSS theo chu kỳ trong một goroutine riêng,
+// đồng thời lấy mẫu tại mỗi ranh giới giai đoạn để các giai đoạn ngắn cũng được ghi nhận.
+// Khác với hiệu Alloc trước và sau khi chạy, đỉnh đo được không bị ảnh hưởng bởi GC.
+type MemoryProfiler struct {
+	interval time.Duration
+
+	mu         sync.Mutex
+	phases     []*PhaseUsage
+	byName     map[string]*PhaseUsage
+	current    *PhaseUsage
+	phaseAlloc uint64 // TotalAlloc khi giai đoạn hiện tại bắt đầu
+	startAlloc uint64
+	report     Report
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+// NewMemoryProfiler tạo profiler lấy mẫu mỗi interval (DefaultInterval nếu interval <= 0)
+func NewMemoryProfiler(interval time.Duration) *MemoryProfiler {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &MemoryProfiler{interval: interval, byName: make(map[string]*PhaseUsage)}
+}
+
+// Start bắt đầu lấy mẫu ở giai đoạn phase
+func (p *MemoryProfiler) Start(phase string) {
+	p.mu.Lock()
+	sample := takeSample()
+	p.startAlloc = sample.totalAlloc
+	p.enter(phase, sample)
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	p.mu.Unlock()
+
+	go p.run()
+}
+
+// StartPhase kết thúc giai đoạn hiện tại và bắt đầu giai đoạn name
+func (p *MemoryProfiler) StartPhase(name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current == nil {
+		return
+	}
+	sample := takeSample()
+	p.record(sample)
+	p.current.Allocated += sample.totalAlloc - p.phaseAlloc
+	p.enter(name, sample)
+}
+
+// Stop dừng lấy mẫu và trả về báo cáo; các giai đoạn theo thứ tự được bắt đầu lần đầu.
+// Gọi Stop thêm lần nữa trả về cùng báo cáo.
+func (p *MemoryProfiler) Stop() Report {
+	p.mu.Lock()
+	if p.current == nil {
+		p.mu.Unlock()
+		return p.report
+	}
+	p.mu.Unlock()
+	close(p.stop)
+	<-p.done
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sample := takeSample()
+	p.record(sample)
+	p.current.Allocated += sample.totalAlloc - p.phaseAlloc
+	p.current = nil
+
+	p.report.Allocated = sample.totalAlloc - p.startAlloc
+	if _, hwm := readRSS(); hwm > p.report.PeakRSS {
+		p.report.PeakRSS = hwm
+	}
+	p.report.Phases = nil
+	for _, phase := range p.phases {
+		p.report.Phases = append(p.report.Phases, *phase)
+	}
+	return p.report
+}
+
+func (p *MemoryProfiler) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			sample := takeSample()
+			p.mu.Lock()
+			p.record(sample)
+			p.mu.Unlock()
+		}
+	}
+}
+
+// enter chuyển sang giai đoạn name; người gọi phải giữ p.mu
+func (p *MemoryProfiler) enter(name string, sample memorySample) {
+	phase, ok := p.byName[name]
+	if !ok {
+		phase = &PhaseUsage{Name: name}
+		p.byName[name] = phase
+		p.phases = append(p.phases, phase)
+	}
+	p.current = phase
+	p.phaseAlloc = sample.totalAlloc
+	p.record(sample)
+}
+
+// record cập nhật các đỉnh của giai đoạn hiện tại và của cả lần chạy; người gọi phải giữ p.mu
+func (p *MemoryProfiler) record(sample memorySample) {
+	if p.current == nil {
+		return
+	}
+	p.current.PeakHeap = max(p.current.PeakHeap, sample.heapInUse)
+	p.current.PeakRSS = max(p.current.PeakRSS, sample.rss)
+	p.report.PeakHeap = max(p.report.PeakHeap, sample.heapInUse)
+	p.report.PeakRSS = max(p.report.PeakRSS, sample.rss)
+}
+
+type memorySample struct {
+	heapInUse  uint64
+	totalAlloc uint64
+	rss        uint64
+}
+
+func takeSample() memorySample {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	rss, _ := readRSS()
+	return memorySample{heapInUse: stats.HeapInuse, totalAlloc: stats.TotalAlloc, rss: rss}
+}
+
+// readRSS đọc RSS hiện tại (VmRSS) và đỉnh RSS của tiến trình (VmHWM) từ /proc/self/status, theo byte.
+// Trả về 0 trên các hệ điều hành không có /proc.
+func readRSS() (rss, peak uint64) {
+	file, err := os.Open("/proc/self/status")
+	if err != nil {
+		return 0, 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || (key != "VmRSS" && key != "VmHWM") {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if key == "VmRSS" {
+			rss = kb * 1024
+		} else {
+			peak = kb * 1024
+		}
+	}
+	return rss, peak
+}
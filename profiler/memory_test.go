@@ -0,0 +1,47 @@
+package profiler
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+var sink []byte
+
+func TestMemoryProfilerRecordsPeakPerPhase(t *testing.T) {
+	const size = 64 << 20
+
+	p := NewMemoryProfiler(time.Millisecond)
+	p.Start("idle")
+	p.StartPhase("allocate")
+	sink = make([]byte, size)
+	for i := range sink {
+		sink[i] = 1
+	}
+	time.Sleep(5 * time.Millisecond)
+	p.StartPhase("release")
+	sink = nil
+	runtime.GC()
+	report := p.Stop()
+
+	if len(report.Phases) != 3 || report.Phases[0].Name != "idle" || report.Phases[1].Name != "allocate" || report.Phases[2].Name != "release" {
+		t.Fatalf("unexpected phases %+v", report.Phases)
+	}
+	allocate := report.Phases[1]
+	if allocate.PeakHeap < size || allocate.Allocated < size {
+		t.Errorf("allocate phase: peak heap %d, allocated %d, want at least %d", allocate.PeakHeap, allocate.Allocated, size)
+	}
+	if report.Phases[0].PeakHeap >= size {
+		t.Errorf("idle phase peak heap %d includes the later allocation", report.Phases[0].PeakHeap)
+	}
+	if report.PeakHeap < allocate.PeakHeap || report.Allocated < allocate.Allocated {
+		t.Errorf("run totals %+v are below the allocate phase %+v", report, allocate)
+	}
+	if runtime.GOOS == "linux" && (report.PeakRSS < size || allocate.PeakRSS < size) {
+		t.Errorf("peak RSS %d (allocate phase %d) is below the %d bytes touched", report.PeakRSS, allocate.PeakRSS, size)
+	}
+
+	if again := p.Stop(); again.PeakHeap != report.PeakHeap || len(again.Phases) != len(report.Phases) {
+		t.Errorf("second Stop returned %+v, want %+v", again, report)
+	}
+}
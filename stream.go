@@ -0,0 +1,75 @@
+package main
+
+import (
+	"EMHUNer/algorithms"
+	"EMHUNer/dataset"
+	"EMHUNer/logger"
+	"flag"
+	"fmt"
+	"os"
+)
+
+func runStream(args []string) error {
+	fs := flag.NewFlagSet("stream", flag.ContinueOnError)
+	input := fs.String("input", "-", "transaction feed in items:TU:utilities format, optionally gzip or bzip2 compressed, - for stdin")
+	window := fs.Int("window", 0, "number of most recent transactions (or batches with -by-batch) kept in the window (required)")
+	slide := fs.Int("slide", 1, "number of transactions read before the window slides")
+	byBatch := fs.Bool("by-batch", false, "count the window in batches of -slide transactions instead of transactions")
+	minUtil := fs.Float64("minutil", 0, "absolute minimum utility threshold")
+	ratio := fs.Float64("ratio", 0, "minimum utility as a fraction in (0, 1] of the total positive utility of the window")
+	closed := fs.Bool("closed", false, "keep only closed HUIs (no proper superset with the same support)")
+	maximal := fs.Bool("maximal", false, "keep only maximal HUIs (no high-utility proper superset)")
+	verbosity := fs.Int("v", 0, "verbosity: 0 only deltas, 1 summary of each phase, 2 trace every search node")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *window <= 0 || *slide <= 0 {
+		return badParameters("-window and -slide must be positive")
+	}
+	if (*minUtil > 0) == (*ratio > 0) || *minUtil < 0 || *ratio < 0 || *ratio > 1 {
+		return badParameters("exactly one of -minutil or -ratio must be set, with -ratio in (0, 1]")
+	}
+
+	if *closed && *maximal {
+		return badParameters("-maximal cannot be combined with -closed")
+	}
+
+	reader, err := dataset.Open(*input, dataset.DefaultBufferSize)
+	if err != nil {
+		return badInput(err)
+	}
+	defer reader.Close()
+
+	miner := algorithms.NewStreamMiner(*window, *minUtil)
+	if *ratio > 0 {
+		miner = algorithms.NewStreamMinerWithRatio(*window, *ratio)
+	}
+	miner.WindowByBatch = *byBatch
+	miner.Closed = *closed
+	miner.Maximal = *maximal
+	miner.Logger = logger.New(os.Stdout, verbosityLevel(*verbosity))
+
+	slides := 0
+	err = miner.ConsumeReader(reader, *slide, func(delta *algorithms.Delta) error {
+		slides++
+		stats := delta.Result.Stats
+		fmt.Printf("Window %d: %d transactions, minUtility = %.2f, %d HUIs (%d added, %d removed, %d updated)\n",
+			slides, stats.Transactions, stats.MinUtility, stats.HighUtilityItemsets,
+			len(delta.Added), len(delta.Removed), len(delta.Updated))
+		for _, hui := range delta.Added {
+			fmt.Printf("  + Itemset: %v, Utility: %.2f\n", hui.Itemset, hui.Utility)
+		}
+		for _, hui := range delta.Removed {
+			fmt.Printf("  - Itemset: %v, Utility: %.2f\n", hui.Itemset, hui.Utility)
+		}
+		for _, hui := range delta.Updated {
+			fmt.Printf("  ~ Itemset: %v, Utility: %.2f\n", hui.Itemset, hui.Utility)
+		}
+		return nil
+	})
+	if err != nil {
+		return badInput(err)
+	}
+	return nil
+}
@@ -1,8 +1,9 @@
 package utility
 
 import (
+	"EMHUNer/logger"
 	"EMHUNer/models"
-	"fmt"
+	"math"
 )
 
 func CalculateTransactionUtility(transaction *models.Transaction) float64 {
@@ -13,38 +14,38 @@ func CalculateTransactionUtility(transaction *models.Transaction) float64 {
 	return totalUtility
 }
 
-func CalculateAndPrintAllTransactionUtilities(transactions []*models.Transaction) {
+func CalculateAndPrintAllTransactionUtilities(transactions []*models.Transaction, log logger.Logger) {
 	for i, transaction := range transactions {
 		tu := CalculateTransactionUtility(transaction)
-		fmt.Printf("Transaction %d TU: %.2f\n", i+1, tu)
+		log.Logf(logger.Summary, "Transaction %d TU: %.2f\n", i+1, tu)
 	}
 }
 
-func CalculateRLUForAllItemsRhoAnDenta(transactions []*models.Transaction, rho, delta map[int]bool, utilityArray *models.UtilityArray) {
+func CalculateRLUForAllItemsRhoAnDenta(transactions []*models.Transaction, rho, delta map[int]bool, utilityArray *models.UtilityArray, log logger.Logger) {
 	combinedSet := UnionMaps(rho, delta)
 
 	for item := range combinedSet {
 		totalRLU := 0.0
-		fmt.Printf("\nCalculating RLU for item: %d\n", item)
+		log.Logf(logger.Node, "\nCalculating RLU for item: %d\n", item)
 
 		for _, transaction := range transactions {
 			if ContainsItem(transaction, item) {
-				fmt.Printf("  Found item %d in transaction: %v\n", item, transaction.Items)
-				rlu := CalculateRemainingResidualUtility(transaction, item)
+				log.Logf(logger.Node, "  Found item %d in transaction: %v\n", item, transaction.Items)
+				rlu := CalculateRemainingResidualUtility(transaction, item, log)
 				totalRLU += rlu
-				fmt.Printf("  RLU for this transaction: %.2f (cumulative RLU: %.2f)\n", rlu, totalRLU)
+				log.Logf(logger.Node, "  RLU for this transaction: %.2f (cumulative RLU: %.2f)\n", rlu, totalRLU)
 			}
 		}
 
 		utilityArray.SetRLU(item, totalRLU)
-		fmt.Printf("Calculated total RLU for item %d: %.2f\n", item, totalRLU)
+		log.Logf(logger.Node, "Calculated total RLU for item %d: %.2f\n", item, totalRLU)
 	}
 }
 
-func CalculateRLUForAllItems(transactions []*models.Transaction, secondary []int, utilityArray *models.UtilityArray) {
+func CalculateRLUForAllItems(transactions []*models.Transaction, secondary []int, utilityArray *models.UtilityArray, log logger.Logger) {
 	for _, item := range secondary {
 		totalRLU := 0.0
-		fmt.Printf("\nCalculating RLU for item: %d\n", item)
+		log.Logf(logger.Node, "\nCalculating RLU for item: %d\n", item)
 
 		for _, transaction := range transactions {
 			if ContainsItem(transaction, item) {
@@ -53,38 +54,38 @@ func CalculateRLUForAllItems(transactions []*models.Transaction, secondary []int
 				remainingUtility := CalculateRemainingUtility(transaction, index+1)
 				totalRLU += itemUtility + remainingUtility
 
-				fmt.Printf("  Found item %d in transaction %v with utility: %.2f, Remaining Residual Utility: %.2f\n",
+				log.Logf(logger.Node, "  Found item %d in transaction %v with utility: %.2f, Remaining Residual Utility: %.2f\n",
 					item, transaction.Items, itemUtility, remainingUtility)
 			}
 		}
 
 		utilityArray.SetRLU(item, totalRLU)
-		fmt.Printf("Calculated total RLU for item %d: %.2f\n", item, totalRLU)
+		log.Logf(logger.Node, "Calculated total RLU for item %d: %.2f\n", item, totalRLU)
 	}
 }
 
-func CalculateRemainingResidualUtility(transaction *models.Transaction, currentItem int) float64 {
+func CalculateRemainingResidualUtility(transaction *models.Transaction, currentItem int, log logger.Logger) float64 {
 	foundCurrentItem := false
 	rru := 0.0
-	fmt.Printf("    Remaining items after %d: ", currentItem)
+	log.Logf(logger.Node, "    Remaining items after %d: ", currentItem)
 
 	for i, item := range transaction.Items {
 		utility := transaction.Utilities[i]
 
 		if foundCurrentItem && utility > 0 {
 			rru += utility
-			fmt.Printf("%d(%.2f) ", item, utility)
+			log.Logf(logger.Node, "%d(%.2f) ", item, utility)
 		}
 
 		if item == currentItem {
 			foundCurrentItem = true
 			if utility > 0 {
 				rru += utility
-				fmt.Printf("    Adding utility of currentItem %d: %.2f\n", currentItem, utility)
+				log.Logf(logger.Node, "    Adding utility of currentItem %d: %.2f\n", currentItem, utility)
 			}
 		}
 	}
-	fmt.Println()
+	log.Logf(logger.Node, "\n")
 	return rru
 }
 
@@ -107,7 +108,7 @@ func CalculateRemainingResidualUtility(transaction *models.Transaction, currentI
 //	}
 //
 // Hàm cải tiến
-func CalculateRTWUForAllItems(itemTransactionMap map[int][]*models.Transaction, combinedSet map[int]bool, eta map[int]bool, utilityArray *models.UtilityArray) {
+func CalculateRTWUForAllItems(itemTransactionMap map[int][]*models.Transaction, combinedSet map[int]bool, eta map[int]bool, rtwu map[int]float64) {
 	finalCombinedSet := UnionMaps(combinedSet, eta)
 
 	for item := range finalCombinedSet {
@@ -122,8 +123,8 @@ func CalculateRTWUForAllItems(itemTransactionMap map[int][]*models.Transaction,
 			}
 		}
 
-		// Cập nhật RTWU cho item trong UtilityArray
-		utilityArray.SetRTWU(item, totalRTWU)
+		// Cập nhật RTWU cho item (theo mã item gốc)
+		rtwu[item] = totalRTWU
 	}
 }
 func CalculateRTUForTransaction(transaction *models.Transaction) float64 {
@@ -136,6 +137,15 @@ func CalculateRTUForTransaction(transaction *models.Transaction) float64 {
 	return rtwu
 }
 
+// CalculateTotalPositiveUtility tính tổng RTU (phần utility dương) của toàn bộ cơ sở dữ liệu
+func CalculateTotalPositiveUtility(transactions []*models.Transaction) float64 {
+	total := 0.0
+	for _, transaction := range transactions {
+		total += CalculateRTUForTransaction(transaction)
+	}
+	return total
+}
+
 //Hàm cũ
 // func CalculateRSUForAllItems(transactions []*models.Transaction, secondary []int, utilityArray *models.UtilityArray) {
 // 	for _, item := range secondary {
@@ -169,7 +179,9 @@ func CalculateRSUForAllItems(itemTransactionMap map[int][]*models.Transaction, s
 				index := GetItemIndex(transaction, item)
 				itemUtility := transaction.Utilities[index]
 				remainingUtility := CalculateRemainingUtility(transaction, index+1)
-				totalRSU += itemUtility + remainingUtility
+				// Phần đóng góp âm của một giao dịch không được trừ vào cận trên:
+				// các itemset mở rộng không chứa giao dịch đó vẫn có thể đạt ngưỡng
+				totalRSU += math.Max(0, itemUtility+remainingUtility)
 			}
 		}
 
@@ -187,142 +199,35 @@ func CalculateRemainingUtility(transaction *models.Transaction, startIndex int)
 	return remainingUtility
 }
 
-// func CalculateRSUForAllItem(transactions []*models.Transaction, X []int, secondary []int, utilityArray *models.UtilityArray) {
-// 	for _, item := range secondary {
-// 		totalRSU := 0.0
-
-// 		for _, transaction := range transactions {
-// 			if ContainsAllItems(transaction, X) && ContainsItem(transaction, item) {
-// 				utilityX := CalculateUtilityForSet(transaction, X)
-// 				indexZ := GetItemIndex(transaction, item)
-// 				utilityZ := transaction.Utilities[indexZ]
-// 				rru := CalculateRemainingUtility(transaction, indexZ+1)
-// 				totalRSU += utilityX + utilityZ + rru
-// 			}
-// 		}
-
-//			utilityArray.SetRSU(item, totalRSU)
-//		}
-//	}
-//
-// Hàm mới
-func CalculateRSUForAllItem(projectedItemTransactionMap map[int][]*models.Transaction, X []int, secondary []int, utilityArray *models.UtilityArray) {
-	for _, item := range secondary {
-		totalRSU := 0.0
-		foundInAnyTransaction := false
-
-		// fmt.Printf("\nTính RSU cho item %d:\n", item)
-
-		// Duyệt qua tất cả các giao dịch trong `projectedItemTransactionMap`
-		for _, transactions := range projectedItemTransactionMap {
-			for _, transaction := range transactions {
-				// Kiểm tra nếu transaction chứa tất cả các item trong X và item hiện tại
-				if ContainsAllItems(transaction, X) && ContainsItem(transaction, item) {
-					foundInAnyTransaction = true // Đánh dấu item có trong ít nhất một giao dịch
-
-					// Tính toán các giá trị cần thiết
-					utilityX := CalculateUtilityForSet(transaction, X)
-					indexZ := GetItemIndex(transaction, item)
-					utilityZ := transaction.Utilities[indexZ]
-					remainingUtility := CalculateRemainingUtility(transaction, indexZ+1)
-
-					// Cộng tổng RSU tạm thời cho item hiện tại
-					totalRSU += utilityX + utilityZ + remainingUtility
-					// fmt.Printf("    Utility(X): %.2f, Utility(%d): %.2f, Remaining Utility: %.2f, RSU Tổng Tạm Thời: %.2f\n", utilityX, item, utilityZ, remainingUtility, totalRSU)
-				}
+// CalculateBoundsForAllItem tính RSU (và RLU nếu withRLU) của X ∪ {z} với mọi z thuộc candidates
+// trong một lượt duyệt cơ sở dữ liệu chiếu của X.
+// Item trong giao dịch và trong candidates phải tăng dần theo thứ tự xử lý.
+// Cận của candidates[i] được lưu ở vị trí i của bounds; chỉ các item đứng sau X được tính.
+func CalculateBoundsForAllItem(projection models.Projection, candidates []int, bounds *models.UtilityArray, withRLU bool) {
+	for _, entry := range projection {
+		transaction, utilityX := entry.Transaction, entry.Utility
+		remainingUtility := CalculateRemainingUtility(transaction, entry.Offset)
+		// RLU của mọi z trong giao dịch này như nhau: utility của X cộng phần dương còn lại sau X
+		rlu := math.Max(0, utilityX+remainingUtility)
+
+		next := 0
+		for i := entry.Offset; i < len(transaction.Items) && next < len(candidates); i++ {
+			item, itemUtility := transaction.Items[i], transaction.Utilities[i]
+			if itemUtility > 0 {
+				remainingUtility -= itemUtility
 			}
-		}
-
-		// Nếu item không có trong bất kỳ giao dịch nào của `projectedItemTransactionMap`, bỏ qua tính toán
-		if !foundInAnyTransaction {
-			// fmt.Printf("Item %d không có trong bất kỳ giao dịch nào của projectedItemTransactionMap.\n", item)
-			continue
-		}
-
-		// Cập nhật RSU cho item trong utilityArray
-		utilityArray.SetRSU(item, totalRSU)
-		// fmt.Printf("RSU(%d) = %.2f\n", item, totalRSU)
-	}
-}
-
-// func CalculateRLUForAllItem(transactions []*models.Transaction, X []int, secondary []int, utilityArray *models.UtilityArray) {
-// 	for _, item := range secondary {
-// 		totalRLU := 0.0
-
-// 		for _, transaction := range transactions {
-// 			if ContainsAllItems(transaction, X) && ContainsItem(transaction, item) {
-// 				utilityX := CalculateUtilityForSet(transaction, X)
-// 				maxIndexX := FindLocationMaxIndexForSet(transaction, X)
-// 				index := GetItemIndex(transaction, maxIndexX)
-
-// 				remainingUtility := CalculateRemainingUtility(transaction, index+1)
-
-// 				totalRLU += utilityX + remainingUtility
-// 			}
-// 		}
-
-//			utilityArray.SetRLU(item, totalRLU)
-//		}
-//	}
-func CalculateRLUForAllItem(projectedItemTransactionMap map[int][]*models.Transaction, X []int, secondary []int, utilityArray *models.UtilityArray) {
-	for _, item := range secondary {
-		totalRLU := 0.0
-		foundInAnyTransaction := false
-
-		// fmt.Printf("\nTính RLU cho item %d:\n", item)
-
-		// Duyệt qua tất cả các giao dịch trong `projectedItemTransactionMap`
-		for _, transactions := range projectedItemTransactionMap {
-			for _, transaction := range transactions {
-				// Kiểm tra nếu transaction chứa tất cả các item trong X và item hiện tại
-				if ContainsAllItems(transaction, X) && ContainsItem(transaction, item) {
-					foundInAnyTransaction = true
-
-					// Tính toán các giá trị cần thiết
-					utilityX := CalculateUtilityForSet(transaction, X)
-					maxIndexX := FindLocationMaxIndexForSet(transaction, X)
-
-					remainingUtility := CalculateRemainingUtility(transaction, maxIndexX+1)
-
-					// Cộng tổng RLU tạm thời cho item hiện tại
-					totalRLU += utilityX + remainingUtility
-					// fmt.Printf("    Utility(X): %.2f, Remaining Utility: %.2f, RLU Tổng Tạm Thời: %.2f\n", utilityX, remainingUtility, totalRLU)
-				}
+			for next < len(candidates) && candidates[next] < item {
+				next++
+			}
+			if next == len(candidates) || candidates[next] != item {
+				continue
+			}
+			bounds.RSUs[next] += math.Max(0, utilityX+itemUtility+remainingUtility)
+			if withRLU {
+				bounds.RLUs[next] += rlu
 			}
-		}
-
-		// Nếu item không có trong bất kỳ giao dịch nào của `projectedItemTransactionMap`, bỏ qua tính toán
-		if !foundInAnyTransaction {
-			// fmt.Printf("Item %d không có trong bất kỳ giao dịch nào của projectedItemTransactionMap.\n", item)
-			continue
-		}
-
-		// Cập nhật RLU cho item trong utilityArray
-		utilityArray.SetRLU(item, totalRLU)
-		// fmt.Printf("RLU(%d) = %.2f\n", item, totalRLU) // In tổng RLU cuối cùng cho item
-	}
-}
-
-func CalculateUtilityForSet(transaction *models.Transaction, X []int) float64 {
-	totalUtility := 0.0
-	for _, item := range X {
-		if ContainsItem(transaction, item) {
-			index := GetItemIndex(transaction, item)
-			totalUtility += transaction.Utilities[index]
-		}
-	}
-	return totalUtility
-}
-
-func FindLocationMaxIndexForSet(transaction *models.Transaction, X []int) int {
-	maxIndex := -1
-	for _, item := range X {
-		index := GetItemIndex(transaction, item)
-		if index > maxIndex {
-			maxIndex = index
 		}
 	}
-	return maxIndex
 }
 
 func ContainsItem(transaction *models.Transaction, item int) bool {
@@ -334,15 +239,6 @@ func ContainsItem(transaction *models.Transaction, item int) bool {
 	return false
 }
 
-func ContainsAllItems(transaction *models.Transaction, X []int) bool {
-	for _, item := range X {
-		if !ContainsItem(transaction, item) {
-			return false
-		}
-	}
-	return true
-}
-
 func GetItemIndex(transaction *models.Transaction, item int) int {
 	for i, tItem := range transaction.Items {
 		if tItem == item {